@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/LETHEVIET/chat-tui/internal/config"
+	"github.com/LETHEVIET/chat-tui/internal/importer"
+	"github.com/LETHEVIET/chat-tui/internal/search"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>...",
+	Short: "Import conversations from ChatGPT or Open WebUI",
+	Long: `Import conversation exports as chat-tui sessions.
+
+Supported formats (detected automatically):
+  ChatGPT     conversations.json from Settings → Data controls → Export
+  Open WebUI  JSON from the chat export (single chat or all chats)
+
+Edits and regenerations are kept as branches, with the branch selected in the
+source application active. Importing the same file again updates the sessions
+instead of duplicating them, unless they were changed in chat-tui since: those
+are skipped, so continuing an imported conversation never loses messages.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().Bool("main-branch", false, "import only the selected branch of each conversation")
+	importCmd.Flags().Bool("dry-run", false, "list the conversations without importing them")
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := session.NewStore(cfg.Sessions.Dir)
+	if err != nil {
+		return err
+	}
+
+	mainOnly, _ := cmd.Flags().GetBool("main-branch")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	total, skipped := 0, 0
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		format, sessions, err := importer.Parse(data, importer.Options{MainBranchOnly: mainOnly})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		imported := 0
+		for _, s := range sessions {
+			title := searchTitleStyle.Render(session.Truncate(s.DisplayTitle(), 60))
+			if changedSinceImport(store, s) {
+				fmt.Printf("  %s %s\n", title,
+					searchMetaStyle.Render(fmt.Sprintf("(%s • changed in chat-tui since the last import, skipped)", s.ID)))
+				skipped++
+				continue
+			}
+			if !dryRun {
+				if err := store.Save(s); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("  %s %s\n", title,
+				searchMetaStyle.Render(fmt.Sprintf("(%s • %d turns • %s)", s.ID, s.Turns(), s.CreatedAt.Local().Format("2006-01-02"))))
+			imported++
+		}
+		fmt.Printf("%d conversations in %s (%s)\n", len(sessions), path, format.Name())
+		total += imported
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d conversations changed since the last import; delete them from %s to import them again\n", skipped, store.Dir())
+	}
+
+	if dryRun || total == 0 {
+		return nil
+	}
+
+	// Index right away so the first search is fast
+	idx, err := search.Open(store)
+	if err != nil {
+		return err
+	}
+	if err := idx.Sync(store); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d conversations into %s\n", total, store.Dir())
+	fmt.Println(searchMetaStyle.Render("Resume one with chat-tui --resume <id>, or find it with chat-tui search <query>"))
+	return nil
+}
+
+// changedSinceImport reports whether s was imported before and then changed
+// in chat-tui, so importing it again would lose those changes
+func changedSinceImport(store *session.Store, s *session.Session) bool {
+	if _, err := store.ModTime(s.ID); err != nil {
+		return false
+	}
+	stored, err := store.Load(s.ID)
+	if err != nil {
+		return false
+	}
+	return importer.LocalChanges(stored, s)
+}
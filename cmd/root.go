@@ -28,6 +28,10 @@ func init() {
 	rootCmd.Flags().Float64P("temperature", "t", 0, "temperature for responses")
 	rootCmd.Flags().StringP("base-url", "u", "", "base URL for API")
 	rootCmd.Flags().BoolP("no-stats", "n", false, "disable stats panel")
+	rootCmd.Flags().Bool("fullscreen", false, "run full-screen with a scrollable transcript")
+	rootCmd.Flags().Bool("continue", false, "continue the most recent session in this directory")
+	rootCmd.Flags().String("resume", "", "resume a saved session by ID")
+	rootCmd.Flags().StringSlice("compare", nil, "compare models side by side (e.g. --compare gpt-4o,llama3@http://localhost:11434/v1)")
 }
 
 func runChat(cmd *cobra.Command, args []string) error {
@@ -54,14 +58,44 @@ func runChat(cmd *cobra.Command, args []string) error {
 		cfg.UI.ShowStats = false
 	}
 
+	if fullscreen, _ := cmd.Flags().GetBool("fullscreen"); fullscreen {
+		cfg.UI.Fullscreen = true
+	}
+
 	// Create chat model
 	chatModel, err := ui.NewChatModel(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create chat model: %w", err)
 	}
+	defer chatModel.Close()
 
-	// Start Bubble Tea program (inline mode, not full-screen)
-	p := tea.NewProgram(chatModel)
+	if resume, _ := cmd.Flags().GetString("resume"); resume != "" {
+		if err := chatModel.ResumeSession(resume); err != nil {
+			return fmt.Errorf("failed to resume session: %w", err)
+		}
+	} else if cont, _ := cmd.Flags().GetBool("continue"); cont {
+		if err := chatModel.ContinueSession(); err != nil {
+			return fmt.Errorf("failed to continue session: %w", err)
+		}
+	}
+
+	if compare, _ := cmd.Flags().GetStringSlice("compare"); len(compare) > 0 {
+		if err := chatModel.StartCompare(compare); err != nil {
+			return err
+		}
+	}
+
+	return runProgram(chatModel)
+}
+
+// runProgram runs the Bubble Tea program for a chat model
+func runProgram(chatModel *ui.ChatModel) error {
+	// Inline mode by default; full-screen uses the alternate screen and the mouse wheel
+	var opts []tea.ProgramOption
+	if chatModel.Fullscreen() {
+		opts = append(opts, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(chatModel, opts...)
 
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("error running program: %w", err)
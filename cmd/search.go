@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/config"
+	"github.com/LETHEVIET/chat-tui/internal/search"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchHighlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
+	searchTitleStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true)
+	searchMetaStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search all saved sessions",
+	Long: `Search the full text of all saved sessions.
+
+Words must all appear in a message; "quoted phrases" must appear verbatim.
+Filters: role:<user|assistant|system>, model:<name>, after:YYYY-MM-DD, before:YYYY-MM-DD`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().IntP("limit", "l", 20, "maximum number of results")
+	searchCmd.Flags().IntP("open", "o", 0, "open result number n in the chat")
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	store, err := session.NewStore(cfg.Sessions.Dir)
+	if err != nil {
+		return err
+	}
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	query := strings.Join(args, " ")
+	q, results, err := search.Find(store, query, limit)
+	if err != nil {
+		return err
+	}
+
+	if open, _ := cmd.Flags().GetInt("open"); open > 0 {
+		if open > len(results) {
+			return fmt.Errorf("result %d not found (%d results)", open, len(results))
+		}
+		result := results[open-1]
+
+		chatModel, err := ui.NewChatModel(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create chat model: %w", err)
+		}
+		defer chatModel.Close()
+
+		if err := chatModel.ResumeSession(result.Session); err != nil {
+			return fmt.Errorf("failed to open session: %w", err)
+		}
+		chatModel.FocusNode(result.Node)
+		return runProgram(chatModel)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No matches for %q\n", query)
+		return nil
+	}
+
+	for i, r := range results {
+		fmt.Printf("%2d. %s %s\n", i+1,
+			searchTitleStyle.Render(session.Truncate(r.Title, 60)),
+			searchMetaStyle.Render(fmt.Sprintf("(%s • %s • %s)", r.Role, r.Model, r.Timestamp.Local().Format("2006-01-02 15:04"))))
+		fmt.Printf("    %s\n", search.Snippet(r.Content, q.HighlightTerms(), 100, func(s string) string {
+			return searchHighlightStyle.Render(s)
+		}))
+		fmt.Printf("    %s\n", searchMetaStyle.Render(fmt.Sprintf("chat-tui search '%s' --open %d  •  chat-tui --resume %s", query, i+1, r.Session)))
+	}
+
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/keymap"
+	"github.com/charmbracelet/bubbles/key"
 )
 
 // CommandDef represents a command definition
@@ -22,16 +25,31 @@ var AvailableCommands = []CommandDef{
 	{Name: "temp", Description: "Set temperature", Usage: "/temp <0-2>"},
 	{Name: "system", Description: "Set system prompt", Usage: "/system <text>"},
 	{Name: "delete", Description: "Delete last turn", Usage: "/delete"},
+	{Name: "undo", Description: "Undo the last change", Usage: "/undo"},
+	{Name: "redo", Description: "Redo the last undone change", Usage: "/redo"},
 	{Name: "save", Description: "Save conversation", Usage: "/save <file>"},
 	{Name: "load", Description: "Load conversation", Usage: "/load <file>"},
-	{Name: "tokens", Description: "Show token usage", Usage: "/tokens"},
+	{Name: "tree", Description: "Show conversation branches", Usage: "/tree"},
+	{Name: "sessions", Description: "Browse saved sessions", Usage: "/sessions"},
+	{Name: "title", Description: "Rename the session", Usage: "/title <text>"},
+	{Name: "find", Description: "Search saved sessions", Usage: "/find <query>"},
+	{Name: "context", Description: "Show context window usage", Usage: "/context"},
+	{Name: "pin", Description: "Pin a message to the context", Usage: "/pin [n]"},
+	{Name: "summarize", Description: "Summarize older turns", Usage: "/summarize"},
+	{Name: "tokens", Description: "Show token usage per turn", Usage: "/tokens"},
 	{Name: "cost", Description: "Show estimated cost", Usage: "/cost"},
-	{Name: "export", Description: "Export as markdown", Usage: "/export"},
-	{Name: "stats", Description: "Toggle stats panel", Usage: "/stats"},
-	{Name: "debug", Description: "Toggle debug mode", Usage: "/debug"},
-	{Name: "retry", Description: "Retry last message", Usage: "/retry"},
-	{Name: "copy", Description: "Copy last response", Usage: "/copy"},
-	{Name: "edit", Description: "Edit last message", Usage: "/edit"},
+	{Name: "export", Description: "Export conversation", Usage: "/export [md|html|json|txt] [path]"},
+	{Name: "stats", Description: "Toggle stats, or the detailed stats pane", Usage: "/stats [panel]"},
+	{Name: "debug", Description: "Toggle debug logging", Usage: "/debug"},
+	{Name: "retry", Description: "Regenerate last response", Usage: "/retry [n]"},
+	{Name: "copy", Description: "Copy last response or a code block", Usage: "/copy [code [n]]"},
+	{Name: "save-code", Description: "Save a code block to a file", Usage: "/save-code <n> <path>"},
+	{Name: "open", Description: "Open a code block in $EDITOR", Usage: "/open [n]"},
+	{Name: "edit", Description: "Edit a message in $EDITOR", Usage: "/edit [n] [--no-run]"},
+	{Name: "compare", Description: "Compare models side by side", Usage: "/compare <a> <b> [c]"},
+	{Name: "pick", Description: "Pick a compare answer", Usage: "/pick <n>"},
+	{Name: "theme", Description: "Switch the color theme", Usage: "/theme [name]"},
+	{Name: "keys", Description: "Show the key bindings", Usage: "/keys"},
 	{Name: "multiline", Description: "Toggle multiline mode", Usage: "/multiline"},
 	{Name: "exit", Description: "Exit the application", Usage: "/exit"},
 }
@@ -72,9 +90,9 @@ func IsCommand(input string) bool {
 	return strings.HasPrefix(strings.TrimSpace(input), "/")
 }
 
-// CommandHelp returns help text for all commands
-func CommandHelp() string {
-	return `Available Commands:
+// CommandHelp returns help text for all commands, naming the keys bound in keys
+func CommandHelp(keys *keymap.KeyMap) string {
+	return fmt.Sprintf(`Available Commands:
 /help           - Show this help message
 /new            - Start a new conversation
 /clear          - Clear chat history (alias for /new)
@@ -82,18 +100,53 @@ func CommandHelp() string {
 /temp <0-1>     - Set temperature (e.g., /temp 0.7)
 /system <text>  - Set system prompt
 /delete         - Delete last turn (user message + assistant response)
-/save <file>    - Save conversation to file
-/load <file>    - Load conversation from file
-/tokens         - Show token usage
-/cost           - Show estimated cost
-/export         - Export conversation as markdown
-/stats          - Toggle stats panel
-/debug          - Toggle debug mode
-/retry          - Retry last message
+/undo           - Undo the last change to the conversation (%[1]s)
+/redo           - Redo the last undone change (%[2]s)
+/save <file>    - Save conversation, model and settings to a JSON file
+/load <file>    - Load a saved conversation and restore its settings
+/tree           - Show the branch structure and jump to any branch
+/sessions       - Browse and reopen autosaved sessions
+/title <text>   - Rename the session (titles are generated automatically)
+/find <query>   - Search all saved sessions ("phrase", role:, model:,
+                  after:YYYY-MM-DD, before:YYYY-MM-DD)
+/context        - Show context window usage and policy
+/pin [n]        - Pin or unpin the last message, or message n; pinned
+                  messages are never dropped from the context
+/summarize      - Replace older turns in the context with a summary
+/tokens         - Show per-turn token usage and session totals
+/cost           - Show the estimated session cost per model
+/export [md|html|json|txt] [path] [--system] [--stats]
+                - Export conversation as Markdown, HTML, OpenAI JSONL or text
+/stats [panel]  - Toggle stats, or the detailed stats pane with session history
+/debug          - Toggle debug logging and show log path
+/retry [n]      - Regenerate the last response (n alternatives concurrently),
+                  keeping earlier ones; %[3]s/%[4]s cycles through them
 /copy           - Copy last response to clipboard
-/edit           - Edit last message
+/copy code [n]  - Copy code block n of the last response (default: the
+                  focused block, or the first; %[5]s cycles focus)
+/save-code <n> <path> - Save code block n to a file (the extension is
+                  inferred from the language when path has none; asks
+                  before overwriting)
+/open [n]       - Open code block n in $EDITOR
+/edit [n] [--no-run] - Edit the last user message, or message n (-1 = last),
+                  in $EDITOR as a new branch; edited user messages are re-run
+/compare <a> <b> [c] - Send each message to several models side by side
+                  (use model@base-url for other endpoints, /compare off to stop)
+/pick <n>       - Continue with the answer from compare column n
+/theme [name]   - Switch the color theme, or list the available themes
+/keys           - Show the key bindings (%[6]s)
 /multiline      - Toggle multiline input mode
-/quit           - Exit the application`
+/quit           - Exit the application`,
+		keyHint(keys.Undo), keyHint(keys.Redo), keyHint(keys.BranchPrev), keyHint(keys.BranchNext),
+		keyHint(keys.CodeFocus), keyHint(keys.Help))
+}
+
+// keyHint names the keys of a binding for help text
+func keyHint(b key.Binding) string {
+	if !b.Enabled() {
+		return "unbound"
+	}
+	return keymap.Describe(b.Keys())
 }
 
 // ValidateArgs validates command arguments
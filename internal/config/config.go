@@ -12,21 +12,51 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	APIKey        string        `mapstructure:"api_key"`
-	BaseURL       string        `mapstructure:"base_url"`
-	Model         string        `mapstructure:"model"`
-	Temperature   float64       `mapstructure:"temperature"`
-	MaxTokens     int           `mapstructure:"max_tokens"`
-	SystemPrompt  string        `mapstructure:"system_prompt"`
-	UI            UIConfig      `mapstructure:"ui"`
-	Debug         DebugConfig   `mapstructure:"debug"`
+	APIKey       string              `mapstructure:"api_key"`
+	BaseURL      string              `mapstructure:"base_url"`
+	Model        string              `mapstructure:"model"`
+	Temperature  float64             `mapstructure:"temperature"`
+	MaxTokens    int                 `mapstructure:"max_tokens"`
+	SystemPrompt string              `mapstructure:"system_prompt"`
+	UI           UIConfig            `mapstructure:"ui"`
+	Context      ContextConfig       `mapstructure:"context"`
+	Sessions     SessionConfig       `mapstructure:"sessions"`
+	Pricing      []ModelPricing      `mapstructure:"pricing"`
+	Keys         map[string][]string `mapstructure:"keys"`
+	Debug        DebugConfig         `mapstructure:"debug"`
 }
 
 // UIConfig holds UI-specific settings
 type UIConfig struct {
-	Theme            string `mapstructure:"theme"`
-	ShowStats        bool   `mapstructure:"show_stats"`
-	SyntaxHighlight  bool   `mapstructure:"syntax_highlight"`
+	Theme           string `mapstructure:"theme"`
+	ShowStats       bool   `mapstructure:"show_stats"`
+	SyntaxHighlight bool   `mapstructure:"syntax_highlight"`
+	LineNumbers     bool   `mapstructure:"line_numbers"`
+	CodeWrap        bool   `mapstructure:"code_wrap"`
+	Fullscreen      bool   `mapstructure:"fullscreen"`
+	MaxWidth        int    `mapstructure:"max_width"`
+}
+
+// ContextConfig holds context-window management settings
+type ContextConfig struct {
+	Policy     string `mapstructure:"policy"`
+	MaxTokens  int    `mapstructure:"max_tokens"`
+	KeepRecent int    `mapstructure:"keep_recent"`
+}
+
+// SessionConfig holds session persistence settings
+type SessionConfig struct {
+	Autosave   bool   `mapstructure:"autosave"`
+	Dir        string `mapstructure:"dir"`
+	AutoTitle  bool   `mapstructure:"auto_title"`
+	TitleModel string `mapstructure:"title_model"`
+}
+
+// ModelPricing is the price of a model in dollars per million tokens
+type ModelPricing struct {
+	Model  string  `mapstructure:"model"`
+	Input  float64 `mapstructure:"input"`
+	Output float64 `mapstructure:"output"`
 }
 
 // DebugConfig holds debug-related settings
@@ -47,6 +77,19 @@ var defaultConfig = Config{
 		Theme:           "dark",
 		ShowStats:       true,
 		SyntaxHighlight: true,
+		LineNumbers:     true,
+		MaxWidth:        120,
+	},
+	Context: ContextConfig{
+		Policy:     "sliding",
+		MaxTokens:  0,
+		KeepRecent: 6,
+	},
+	Sessions: SessionConfig{
+		Autosave:   true,
+		Dir:        "",
+		AutoTitle:  true,
+		TitleModel: "",
 	},
 	Debug: DebugConfig{
 		Verbose: false,
@@ -104,7 +147,7 @@ func Load() (*Config, error) {
 		}
 
 		fmt.Println("\nConfiguration saved to .chat-tui.yaml")
-		fmt.Println("Starting chat...\n")
+		fmt.Print("Starting chat...\n\n")
 
 		return config, nil
 	}
@@ -134,6 +177,17 @@ func setDefaults() {
 	viper.SetDefault("ui.theme", defaultConfig.UI.Theme)
 	viper.SetDefault("ui.show_stats", defaultConfig.UI.ShowStats)
 	viper.SetDefault("ui.syntax_highlight", defaultConfig.UI.SyntaxHighlight)
+	viper.SetDefault("ui.line_numbers", defaultConfig.UI.LineNumbers)
+	viper.SetDefault("ui.code_wrap", defaultConfig.UI.CodeWrap)
+	viper.SetDefault("ui.fullscreen", defaultConfig.UI.Fullscreen)
+	viper.SetDefault("ui.max_width", defaultConfig.UI.MaxWidth)
+	viper.SetDefault("context.policy", defaultConfig.Context.Policy)
+	viper.SetDefault("context.max_tokens", defaultConfig.Context.MaxTokens)
+	viper.SetDefault("context.keep_recent", defaultConfig.Context.KeepRecent)
+	viper.SetDefault("sessions.autosave", defaultConfig.Sessions.Autosave)
+	viper.SetDefault("sessions.dir", defaultConfig.Sessions.Dir)
+	viper.SetDefault("sessions.auto_title", defaultConfig.Sessions.AutoTitle)
+	viper.SetDefault("sessions.title_model", defaultConfig.Sessions.TitleModel)
 	viper.SetDefault("debug.verbose", defaultConfig.Debug.Verbose)
 	viper.SetDefault("debug.log_file", defaultConfig.Debug.LogFile)
 }
@@ -150,9 +204,35 @@ max_tokens: 4096
 system_prompt: "You are a helpful assistant"
 
 ui:
-  theme: dark  # or light
+  theme: dark  # dark, light, high-contrast or a user theme
   show_stats: true
   syntax_highlight: true
+  line_numbers: true  # Number the lines of code blocks
+  code_wrap: false  # Wrap long code lines instead of truncating them
+  fullscreen: false  # Alternate screen with a scrollable transcript
+  max_width: 120  # Wrap messages at most this wide (0 = terminal width)
+
+context:
+  policy: sliding  # none, sliding or summarize
+  max_tokens: 0  # Context budget in tokens (0 = unlimited)
+  keep_recent: 6  # Messages never summarized
+
+sessions:
+  autosave: true
+  dir: ""  # Defaults to $XDG_DATA_HOME/chat-tui/sessions
+  auto_title: true  # Name sessions after the first exchange
+  title_model: ""  # Model used for titles (defaults to the chat model)
+
+# Prices in dollars per million tokens, used for cost estimates
+# pricing:
+#   - model: gpt-4o
+#     input: 2.50
+#     output: 10.00
+
+# Key bindings overriding the defaults; press F1 for the full list
+# keys:
+#   multiline: [alt+m]
+#   quit: [ctrl+q]
 
 debug:
   verbose: false
@@ -165,7 +245,7 @@ debug:
 // InteractiveSetup prompts the user for configuration values
 func InteractiveSetup() (*Config, error) {
 	fmt.Println("Welcome to Chat TUI!")
-	fmt.Println("No configuration file found. Let's set one up.\n")
+	fmt.Print("No configuration file found. Let's set one up.\n\n")
 
 	reader := bufio.NewReader(os.Stdin)
 	config := defaultConfig
@@ -253,9 +333,35 @@ max_tokens: %d
 system_prompt: "%s"
 
 ui:
-  theme: %s  # or light
+  theme: %s  # dark, light, high-contrast or a user theme
   show_stats: %t
   syntax_highlight: %t
+  line_numbers: %t  # Number the lines of code blocks
+  code_wrap: %t  # Wrap long code lines instead of truncating them
+  fullscreen: %t  # Alternate screen with a scrollable transcript
+  max_width: %d  # Wrap messages at most this wide (0 = terminal width)
+
+context:
+  policy: %s  # none, sliding or summarize
+  max_tokens: %d  # Context budget in tokens (0 = unlimited)
+  keep_recent: %d  # Messages never summarized
+
+sessions:
+  autosave: %t
+  dir: "%s"  # Defaults to $XDG_DATA_HOME/chat-tui/sessions
+  auto_title: %t  # Name sessions after the first exchange
+  title_model: "%s"  # Model used for titles (defaults to the chat model)
+
+# Prices in dollars per million tokens, used for cost estimates
+# pricing:
+#   - model: gpt-4o
+#     input: 2.50
+#     output: 10.00
+
+# Key bindings overriding the defaults; press F1 for the full list
+# keys:
+#   multiline: [alt+m]
+#   quit: [ctrl+q]
 
 debug:
   verbose: %t
@@ -270,6 +376,17 @@ debug:
 		cfg.UI.Theme,
 		cfg.UI.ShowStats,
 		cfg.UI.SyntaxHighlight,
+		cfg.UI.LineNumbers,
+		cfg.UI.CodeWrap,
+		cfg.UI.Fullscreen,
+		cfg.UI.MaxWidth,
+		cfg.Context.Policy,
+		cfg.Context.MaxTokens,
+		cfg.Context.KeepRecent,
+		cfg.Sessions.Autosave,
+		cfg.Sessions.Dir,
+		cfg.Sessions.AutoTitle,
+		cfg.Sessions.TitleModel,
 		cfg.Debug.Verbose,
 		cfg.Debug.LogFile,
 	)
@@ -290,6 +407,27 @@ func (c *Config) Save() error {
 	viper.Set("ui.theme", c.UI.Theme)
 	viper.Set("ui.show_stats", c.UI.ShowStats)
 	viper.Set("ui.syntax_highlight", c.UI.SyntaxHighlight)
+	viper.Set("ui.line_numbers", c.UI.LineNumbers)
+	viper.Set("ui.code_wrap", c.UI.CodeWrap)
+	viper.Set("ui.fullscreen", c.UI.Fullscreen)
+	viper.Set("ui.max_width", c.UI.MaxWidth)
+	viper.Set("context.policy", c.Context.Policy)
+	viper.Set("context.max_tokens", c.Context.MaxTokens)
+	viper.Set("context.keep_recent", c.Context.KeepRecent)
+	viper.Set("sessions.autosave", c.Sessions.Autosave)
+	viper.Set("sessions.dir", c.Sessions.Dir)
+	viper.Set("sessions.auto_title", c.Sessions.AutoTitle)
+	viper.Set("sessions.title_model", c.Sessions.TitleModel)
+	if len(c.Pricing) > 0 {
+		pricing := make([]map[string]interface{}, len(c.Pricing))
+		for i, p := range c.Pricing {
+			pricing[i] = map[string]interface{}{"model": p.Model, "input": p.Input, "output": p.Output}
+		}
+		viper.Set("pricing", pricing)
+	}
+	if len(c.Keys) > 0 {
+		viper.Set("keys", c.Keys)
+	}
 	viper.Set("debug.verbose", c.Debug.Verbose)
 	viper.Set("debug.log_file", c.Debug.LogFile)
 
@@ -0,0 +1,168 @@
+package contextwin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// Context policies
+const (
+	PolicyNone      = "none"
+	PolicySliding   = "sliding"
+	PolicySummarize = "summarize"
+)
+
+// Status tells how a message is represented in the request context
+type Status int
+
+const (
+	Included Status = iota
+	Dropped
+	Summarized
+)
+
+// Window is the context built for one request
+type Window struct {
+	Messages []llm.Message
+	Status   []Status
+	Tokens   int
+	Budget   int
+}
+
+// messageTokens approximates the tokens used by one message
+func messageTokens(msg session.Message) int {
+	return llm.EstimateTokens(msg.Content) + llm.MessageOverhead
+}
+
+// ValidPolicy reports whether policy is a known context policy
+func ValidPolicy(policy string) bool {
+	switch policy {
+	case "", PolicyNone, PolicySliding, PolicySummarize:
+		return true
+	}
+	return false
+}
+
+// SummaryPrefix introduces the summary of earlier turns in the request
+const SummaryPrefix = "Summary of the earlier conversation:\n\n"
+
+// Build selects the messages sent for a request. System and pinned messages
+// are always kept. Messages before the latest summary are replaced by it, and
+// with a budget the oldest remaining messages are dropped until it fits.
+func Build(messages []session.Message, policy string, budget int) *Window {
+	w := &Window{
+		Status: make([]Status, len(messages)),
+		Budget: budget,
+	}
+
+	// Apply the latest summary
+	summaryAt := -1
+	if policy != PolicyNone {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Summary != "" {
+				summaryAt = i
+				break
+			}
+		}
+	}
+	for i := 0; i < summaryAt; i++ {
+		if !alwaysKept(messages[i]) {
+			w.Status[i] = Summarized
+		}
+	}
+
+	// Slide the window until it fits the budget
+	if budget > 0 && policy != PolicyNone {
+		total := 0
+		for i, msg := range messages {
+			if w.Status[i] == Included {
+				total += messageTokens(msg)
+			}
+		}
+		if summaryAt >= 0 {
+			total += llm.EstimateTokens(SummaryPrefix+messages[summaryAt].Summary) + llm.MessageOverhead
+		}
+
+		// Never drop the latest message: it is what we are asking about
+		for i := 0; i < len(messages)-1 && total > budget; i++ {
+			if w.Status[i] != Included || alwaysKept(messages[i]) {
+				continue
+			}
+			w.Status[i] = Dropped
+			total -= messageTokens(messages[i])
+		}
+	}
+
+	for i, msg := range messages {
+		if i == summaryAt {
+			summary := session.NewMessage("system", SummaryPrefix+msg.Summary)
+			w.Messages = append(w.Messages, llm.Message{Role: summary.Role, Content: summary.Content})
+			w.Tokens += messageTokens(summary)
+		}
+		if w.Status[i] == Included {
+			w.Messages = append(w.Messages, llm.Message{Role: msg.Role, Content: msg.Content})
+			w.Tokens += messageTokens(msg)
+		}
+	}
+
+	return w
+}
+
+// alwaysKept reports whether a message is never dropped or summarized
+func alwaysKept(msg session.Message) bool {
+	return msg.Role == "system" || msg.Pinned
+}
+
+// SummaryRange returns the path indices [start, end) that a new summary
+// should cover, keeping the last keepRecent messages verbatim. ok is false
+// when there is nothing worth summarizing.
+func SummaryRange(messages []session.Message, keepRecent int) (start, end int, ok bool) {
+	start = 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Summary != "" {
+			start = i
+			break
+		}
+	}
+
+	// The latest message is always kept
+	end = len(messages) - max(keepRecent, 1)
+	if end <= start {
+		return 0, 0, false
+	}
+
+	for i := start; i < end; i++ {
+		if !alwaysKept(messages[i]) {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// SummaryRequest builds the side request that summarizes messages[start:end],
+// folding in the summary they already carry
+func SummaryRequest(messages []session.Message, start, end int) []llm.Message {
+	var transcript strings.Builder
+	if prev := messages[start].Summary; prev != "" {
+		transcript.WriteString("Earlier summary:\n" + prev + "\n\n")
+	}
+	for _, msg := range messages[start:end] {
+		if alwaysKept(msg) {
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", msg.Role, msg.Content)
+	}
+
+	return []llm.Message{
+		{
+			Role: "system",
+			Content: "You summarize conversations so they can be continued with less context. " +
+				"Write a concise summary that preserves facts, decisions, code identifiers, open questions " +
+				"and user preferences. Reply with the summary only.",
+		},
+		{Role: "user", Content: transcript.String()},
+	}
+}
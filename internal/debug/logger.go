@@ -0,0 +1,149 @@
+package debug
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secret values in the log file
+const Redacted = "[REDACTED]"
+
+// secretKeys lists attribute keys whose values are always redacted
+var secretKeys = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"api_key":             true,
+	"apikey":              true,
+	"api-key":             true,
+	"x-api-key":           true,
+}
+
+// secretPatterns match secrets embedded in free-form strings such as request bodies
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)bearer\s+[^\s"]+`),
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{8,}`),
+	regexp.MustCompile(`(?i)("api_?key"\s*:\s*)"[^"]*"`),
+}
+
+// levelOff is above every level the client logs at, so nothing is written
+const levelOff = slog.LevelError + 4
+
+// Logger writes structured JSON debug logs to a file
+type Logger struct {
+	path   string
+	mu     sync.Mutex
+	file   *os.File
+	level  *slog.LevelVar
+	logger *slog.Logger
+}
+
+// NewLogger creates a logger writing to path while verbose logging is enabled.
+// The file is only created once logging is first enabled, at start-up or with
+// SetVerbose. An empty path disables logging entirely.
+func NewLogger(path string, verbose bool) (*Logger, error) {
+	l := &Logger{
+		path:  path,
+		level: new(slog.LevelVar),
+	}
+	l.level.Set(levelOff)
+
+	if path == "" {
+		l.logger = slog.New(slog.DiscardHandler)
+		return l, nil
+	}
+	l.logger = slog.New(slog.NewJSONHandler(l, &slog.HandlerOptions{
+		Level:       l.level,
+		ReplaceAttr: redactAttr,
+	}))
+
+	if err := l.SetVerbose(verbose); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Write appends a log record to the file, if it is open
+func (l *Logger) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return len(p), nil
+	}
+	return l.file.Write(p)
+}
+
+// Slog returns the underlying structured logger
+func (l *Logger) Slog() *slog.Logger {
+	return l.logger
+}
+
+// Path returns the log file path
+func (l *Logger) Path() string {
+	return l.path
+}
+
+// Verbose reports whether verbose logging is enabled
+func (l *Logger) Verbose() bool {
+	return l.level.Level() <= slog.LevelDebug
+}
+
+// SetVerbose enables or disables logging at runtime, opening the log file the
+// first time it is enabled
+func (l *Logger) SetVerbose(verbose bool) error {
+	if !verbose || l.path == "" {
+		l.level.Set(levelOff)
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		l.file = f
+	}
+	l.level.Set(slog.LevelDebug)
+	return nil
+}
+
+// Close closes the log file
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// RedactString masks bearer tokens and API keys found in s
+func RedactString(s string) string {
+	for _, re := range secretPatterns {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			if sub := re.FindStringSubmatch(match); len(sub) > 1 {
+				return sub[1] + `"` + Redacted + `"`
+			}
+			return Redacted
+		})
+	}
+	return s
+}
+
+// redactAttr is the slog ReplaceAttr hook that strips secrets from every record
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if secretKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	}
+	return a
+}
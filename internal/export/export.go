@@ -0,0 +1,246 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+	FormatText     Format = "txt"
+)
+
+// Options control what is included in an export
+type Options struct {
+	Title         string
+	IncludeSystem bool
+	IncludeStats  bool
+}
+
+// ParseFormat parses a format name such as "md" or "markdown"
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "json", "jsonl":
+		return FormatJSON, nil
+	case "txt", "text":
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("unknown export format: %s (use md, html, json or txt)", name)
+	}
+}
+
+// FormatFromPath infers the export format from a file extension
+func FormatFromPath(path string) (Format, bool) {
+	format, err := ParseFormat(filepath.Ext(path))
+	return format, err == nil
+}
+
+// Extension returns the file extension for a format
+func (f Format) Extension() string {
+	if f == FormatJSON {
+		return ".jsonl"
+	}
+	return "." + string(f)
+}
+
+// maxSlugLength caps the title part of export filenames
+const maxSlugLength = 40
+
+// DefaultFilename returns a timestamped filename for an export, including
+// the session title when there is one
+func DefaultFilename(format Format, title string, now time.Time) string {
+	name := "chat-"
+	if slug := Slug(title); slug != "" {
+		name += slug + "-"
+	}
+	return name + now.Format("20060102-150405") + format.Extension()
+}
+
+// Slug turns a title into a lowercase, dash-separated filename part
+func Slug(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	slug := strings.Join(words, "-")
+	if runes := []rune(slug); len(runes) > maxSlugLength {
+		slug = strings.TrimRight(string(runes[:maxSlugLength]), "-")
+	}
+	return slug
+}
+
+// Render renders messages in the given format
+func Render(format Format, messages []session.Message, opts Options) ([]byte, error) {
+	messages = filterMessages(messages, opts)
+
+	switch format {
+	case FormatMarkdown:
+		return []byte(renderMarkdown(messages, opts)), nil
+	case FormatHTML:
+		return renderHTML(messages, opts)
+	case FormatJSON:
+		return renderJSON(messages, opts)
+	case FormatText:
+		return []byte(renderText(messages, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+}
+
+// filterMessages drops system messages unless requested
+func filterMessages(messages []session.Message, opts Options) []session.Message {
+	if opts.IncludeSystem {
+		return messages
+	}
+
+	var filtered []session.Message
+	for _, msg := range messages {
+		if msg.Role != "system" {
+			filtered = append(filtered, msg)
+		}
+	}
+	return filtered
+}
+
+// roleTitle returns the display name of a role
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "system":
+		return "System"
+	default:
+		return role
+	}
+}
+
+// statsLine summarizes the stats of a turn
+func statsLine(stats *llm.RequestStats) string {
+	if stats == nil {
+		return ""
+	}
+
+	var parts []string
+	if stats.Model != "" {
+		parts = append(parts, stats.Model)
+	}
+	if stats.TotalTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tok", stats.TotalTokens))
+	} else if stats.OutputTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tok", stats.OutputTokens))
+	}
+	if stats.TimeToFirstToken > 0 {
+		parts = append(parts, fmt.Sprintf("TTFT %.2fs", stats.TimeToFirstToken.Seconds()))
+	}
+	if stats.PostFirstTokenSpeed > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f tok/s", stats.PostFirstTokenSpeed))
+	} else if stats.TokensPerSec > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f tok/s", stats.TokensPerSec))
+	}
+	if stats.Latency > 0 {
+		parts = append(parts, fmt.Sprintf("%.2fs", stats.Latency.Seconds()))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// renderMarkdown renders messages as Markdown, keeping fenced code as-is
+func renderMarkdown(messages []session.Message, opts Options) string {
+	var out strings.Builder
+
+	if opts.Title != "" {
+		out.WriteString("# " + opts.Title + "\n\n")
+	}
+
+	for i, msg := range messages {
+		if i > 0 {
+			out.WriteString("\n---\n\n")
+		}
+		out.WriteString("## " + roleTitle(msg.Role) + "\n\n")
+		out.WriteString(strings.TrimRight(msg.Content, "\n"))
+		out.WriteString("\n")
+		if opts.IncludeStats {
+			if line := statsLine(msg.Stats); line != "" {
+				out.WriteString("\n*" + line + "*\n")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// renderText renders messages as plain text
+func renderText(messages []session.Message, opts Options) string {
+	var out strings.Builder
+
+	if opts.Title != "" {
+		out.WriteString(opts.Title + "\n" + strings.Repeat("=", len(opts.Title)) + "\n\n")
+	}
+
+	for i, msg := range messages {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString(roleTitle(msg.Role) + ":\n")
+		out.WriteString(strings.TrimRight(msg.Content, "\n"))
+		out.WriteString("\n")
+		if opts.IncludeStats {
+			if line := statsLine(msg.Stats); line != "" {
+				out.WriteString("[" + line + "]\n")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+// jsonMessage is a message in the OpenAI chat format
+type jsonMessage struct {
+	Role    string            `json:"role"`
+	Content string            `json:"content"`
+	Stats   *llm.RequestStats `json:"stats,omitempty"`
+}
+
+// renderJSON renders the conversation as one JSONL record in the OpenAI
+// fine-tuning shape: {"messages": [{"role": ..., "content": ...}]}
+func renderJSON(messages []session.Message, opts Options) ([]byte, error) {
+	record := struct {
+		Messages []jsonMessage `json:"messages"`
+	}{
+		Messages: make([]jsonMessage, 0, len(messages)),
+	}
+
+	for _, msg := range messages {
+		jm := jsonMessage{Role: msg.Role, Content: msg.Content}
+		if opts.IncludeStats {
+			jm.Stats = msg.Stats
+		}
+		record.Messages = append(record.Messages, jm)
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(record); err != nil {
+		return nil, fmt.Errorf("failed to marshal export: %w", err)
+	}
+	return out.Bytes(), nil
+}
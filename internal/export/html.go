@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/alecthomas/chroma"
+	chromahtml "github.com/alecthomas/chroma/formatters/html"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// codeStyle is the chroma style used for highlighted code in HTML exports
+const codeStyle = "monokai"
+
+const pageCSS = `
+body { margin: 0; background: #1e1f22; color: #dcdcdc; font: 15px/1.6 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+main { max-width: 860px; margin: 0 auto; padding: 32px 20px; }
+h1 { font-size: 1.6em; border-bottom: 1px solid #3a3b3f; padding-bottom: 8px; }
+.message { margin: 20px 0; padding: 12px 16px; border-radius: 8px; background: #2a2b2f; }
+.message.user { border-left: 4px solid #5fd7d7; }
+.message.assistant { border-left: 4px solid #ff87d7; }
+.message.system { border-left: 4px solid #808080; font-style: italic; }
+.role { font-weight: bold; font-size: 0.85em; text-transform: uppercase; letter-spacing: 0.05em; color: #a0a0a0; }
+.stats { margin-top: 8px; font-size: 0.8em; color: #808080; }
+pre { padding: 12px; border-radius: 6px; overflow-x: auto; }
+code { font-family: "JetBrains Mono", Menlo, Consolas, monospace; font-size: 0.9em; }
+:not(pre) > code { background: #3a3b3f; padding: 1px 4px; border-radius: 4px; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #3a3b3f; padding: 4px 8px; }
+a { color: #5fafff; }
+`
+
+// renderHTML renders messages as a standalone HTML page with highlighted code
+func renderHTML(messages []session.Message, opts Options) ([]byte, error) {
+	style := styles.Get(codeStyle)
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithRendererOptions(
+			renderer.WithNodeRenderers(util.Prioritized(&codeBlockRenderer{formatter: formatter, style: style}, 100)),
+		),
+	)
+
+	var css bytes.Buffer
+	if err := formatter.WriteCSS(&css, style); err != nil {
+		return nil, fmt.Errorf("failed to write code CSS: %w", err)
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = "Chat export"
+	}
+
+	var out bytes.Buffer
+	out.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	out.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	fmt.Fprintf(&out, "<title>%s</title>\n", html.EscapeString(title))
+	out.WriteString("<style>" + pageCSS + css.String() + "</style>\n</head>\n<body>\n<main>\n")
+	fmt.Fprintf(&out, "<h1>%s</h1>\n", html.EscapeString(title))
+
+	for _, msg := range messages {
+		fmt.Fprintf(&out, "<section class=\"message %s\">\n", html.EscapeString(msg.Role))
+		fmt.Fprintf(&out, "<div class=\"role\">%s</div>\n", html.EscapeString(roleTitle(msg.Role)))
+
+		if msg.Role == "user" {
+			// User messages are plain text, not markdown
+			fmt.Fprintf(&out, "<p>%s</p>\n", strings.ReplaceAll(html.EscapeString(msg.Content), "\n", "<br>\n"))
+		} else if err := md.Convert([]byte(msg.Content), &out); err != nil {
+			return nil, fmt.Errorf("failed to render message: %w", err)
+		}
+
+		if opts.IncludeStats {
+			if line := statsLine(msg.Stats); line != "" {
+				fmt.Fprintf(&out, "<div class=\"stats\">%s</div>\n", html.EscapeString(line))
+			}
+		}
+		out.WriteString("</section>\n")
+	}
+
+	out.WriteString("</main>\n</body>\n</html>\n")
+	return out.Bytes(), nil
+}
+
+// codeBlockRenderer renders fenced code blocks with chroma
+type codeBlockRenderer struct {
+	formatter *chromahtml.Formatter
+	style     *chroma.Style
+}
+
+// RegisterFuncs implements renderer.NodeRenderer
+func (r *codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r *codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	block := node.(*ast.FencedCodeBlock)
+	var code strings.Builder
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	lexer := lexers.Get(string(block.Language(source)))
+	if lexer == nil {
+		lexer = lexers.Analyse(code.String())
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code.String())
+	if err != nil {
+		fmt.Fprintf(w, "<pre><code>%s</code></pre>\n", html.EscapeString(code.String()))
+		return ast.WalkSkipChildren, nil
+	}
+	if err := r.formatter.Format(w, r.style, iterator); err != nil {
+		return ast.WalkStop, err
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,129 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// chatGPTConversation is one conversation of a ChatGPT conversations.json export
+type chatGPTConversation struct {
+	ID               string                 `json:"id"`
+	ConversationID   string                 `json:"conversation_id"`
+	Title            string                 `json:"title"`
+	CreateTime       float64                `json:"create_time"`
+	UpdateTime       float64                `json:"update_time"`
+	CurrentNode      string                 `json:"current_node"`
+	DefaultModelSlug string                 `json:"default_model_slug"`
+	Mapping          map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	ID       string          `json:"id"`
+	Parent   *string         `json:"parent"`
+	Children []string        `json:"children"`
+	Message  *chatGPTMessage `json:"message"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+		Text        string            `json:"text"`
+	} `json:"content"`
+	Recipient string `json:"recipient"`
+	Metadata  struct {
+		ModelSlug        string `json:"model_slug"`
+		IsVisuallyHidden bool   `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+// parseChatGPT converts ChatGPT conversations
+func parseChatGPT(items []json.RawMessage, opts Options) ([]*session.Session, error) {
+	sessions := make([]*session.Session, 0, len(items))
+	for i, item := range items {
+		var conv chatGPTConversation
+		if err := json.Unmarshal(item, &conv); err != nil {
+			return nil, fmt.Errorf("failed to parse conversation %d: %w", i+1, err)
+		}
+
+		model := conv.DefaultModelSlug
+		nodes := make(map[string]*node, len(conv.Mapping))
+		for id, n := range conv.Mapping {
+			converted := &node{children: n.Children}
+			if n.Parent != nil {
+				converted.parent = *n.Parent
+			}
+			if msg := convertChatGPTMessage(n.Message); msg != nil {
+				converted.message = msg
+				if model == "" && n.Message.Metadata.ModelSlug != "" {
+					model = n.Message.Metadata.ModelSlug
+				}
+			}
+			nodes[id] = converted
+		}
+
+		tree := buildTree(nodes, conv.CurrentNode, opts.MainBranchOnly)
+		if tree.Len() == 0 {
+			continue
+		}
+
+		sourceID := conv.ConversationID
+		if sourceID == "" {
+			sourceID = conv.ID
+		}
+		if sourceID == "" {
+			sourceID = conv.Title + conv.CurrentNode
+		}
+		sessions = append(sessions, newSession(FormatChatGPT, sourceID, conv.Title, model,
+			unixTime(conv.CreateTime), unixTime(conv.UpdateTime), tree))
+	}
+	return sessions, nil
+}
+
+// convertChatGPTMessage returns the chat message of a mapping entry, or nil
+// for tool traffic, hidden messages and non-text content
+func convertChatGPTMessage(m *chatGPTMessage) *session.Message {
+	if m == nil || m.Metadata.IsVisuallyHidden {
+		return nil
+	}
+	switch m.Author.Role {
+	case "user", "assistant", "system":
+	default:
+		return nil
+	}
+	if m.Recipient != "" && m.Recipient != "all" {
+		// Calls to browsing, code interpreter and other tools
+		return nil
+	}
+
+	var text []string
+	switch m.Content.ContentType {
+	case "text", "multimodal_text":
+		for _, part := range m.Content.Parts {
+			var s string
+			if err := json.Unmarshal(part, &s); err == nil && strings.TrimSpace(s) != "" {
+				text = append(text, s)
+			}
+		}
+	case "code":
+		if m.Content.Text != "" {
+			text = append(text, "```\n"+m.Content.Text+"\n```")
+		}
+	}
+	if len(text) == 0 {
+		return nil
+	}
+
+	msg := session.NewMessage(m.Author.Role, strings.Join(text, "\n\n"))
+	if ts := unixTime(m.CreateTime); !ts.IsZero() {
+		msg.Timestamp = ts
+	}
+	return &msg
+}
@@ -0,0 +1,223 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// Format is a supported export format
+type Format string
+
+const (
+	FormatChatGPT   Format = "chatgpt"
+	FormatOpenWebUI Format = "openwebui"
+)
+
+// Name returns the display name of the format
+func (f Format) Name() string {
+	switch f {
+	case FormatChatGPT:
+		return "ChatGPT"
+	case FormatOpenWebUI:
+		return "Open WebUI"
+	}
+	return string(f)
+}
+
+// Options controls how conversations are converted
+type Options struct {
+	// MainBranchOnly keeps only the branch that was selected in the source
+	// application instead of every edit and regeneration
+	MainBranchOnly bool
+}
+
+// Parse detects the format of an export and converts every conversation in it
+func Parse(data []byte, opts Options) (Format, []*session.Session, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		// Single conversation exports are a bare object
+		var item json.RawMessage
+		if err := json.Unmarshal(data, &item); err != nil {
+			return "", nil, fmt.Errorf("failed to parse export: %w", err)
+		}
+		items = []json.RawMessage{item}
+	}
+	if len(items) == 0 {
+		return "", nil, fmt.Errorf("export contains no conversations")
+	}
+
+	var probe struct {
+		Mapping json.RawMessage `json:"mapping"`
+		Chat    json.RawMessage `json:"chat"`
+		History json.RawMessage `json:"history"`
+	}
+	if err := json.Unmarshal(items[0], &probe); err != nil {
+		return "", nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+
+	var (
+		format   Format
+		sessions []*session.Session
+		err      error
+	)
+	switch {
+	case probe.Mapping != nil:
+		format = FormatChatGPT
+		sessions, err = parseChatGPT(items, opts)
+	case probe.Chat != nil || probe.History != nil:
+		format = FormatOpenWebUI
+		sessions, err = parseOpenWebUI(items, opts)
+	default:
+		return "", nil, fmt.Errorf("unrecognized export format (expected ChatGPT conversations.json or Open WebUI JSON)")
+	}
+	if err != nil {
+		return format, nil, err
+	}
+	return format, sessions, nil
+}
+
+// node is a message of a source conversation tree; message is nil for
+// entries that are not imported (tool calls, hidden system messages)
+type node struct {
+	parent   string
+	children []string
+	message  *session.Message
+}
+
+// buildTree converts a source tree into a conversation tree whose active path
+// leads to current. Skipped entries are removed, their children moving up.
+func buildTree(nodes map[string]*node, current string, mainOnly bool) *session.Tree {
+	tree := session.NewTree()
+
+	if mainOnly {
+		var path []string
+		seen := map[string]bool{}
+		for id := current; id != "" && !seen[id]; id = nodeParent(nodes, id) {
+			seen[id] = true
+			path = append(path, id)
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			if msg := nodes[path[i]].message; msg != nil {
+				tree.Append(*msg)
+			}
+		}
+		return tree
+	}
+
+	var roots []string
+	for id, n := range nodes {
+		if nodes[n.parent] == nil {
+			roots = append(roots, id)
+		}
+	}
+	sort.Strings(roots)
+
+	ids := map[string]int{}
+	var visit func(id string, parent int)
+	visit = func(id string, parent int) {
+		if _, done := ids[id]; done {
+			return
+		}
+		n := nodes[id]
+		ids[id] = parent
+		if n.message != nil {
+			parent = tree.AddChild(parent, *n.message)
+			ids[id] = parent
+		}
+		for _, child := range n.children {
+			if nodes[child] != nil {
+				visit(child, parent)
+			}
+		}
+	}
+	for _, root := range roots {
+		visit(root, -1)
+	}
+
+	// Follow the branch that was selected in the source application
+	if id, ok := ids[current]; ok && id >= 0 {
+		tree.Activate(id)
+	}
+
+	return tree
+}
+
+// nodeParent returns the parent of id, or "" at the root
+func nodeParent(nodes map[string]*node, id string) string {
+	if n := nodes[id]; n != nil {
+		return n.parent
+	}
+	return ""
+}
+
+// newSession fills in the fields shared by every imported session
+func newSession(format Format, sourceID, title, model string, created, updated time.Time, tree *session.Tree) *session.Session {
+	messages := tree.Messages()
+	if created.IsZero() && len(messages) > 0 {
+		created = messages[0].Timestamp
+	}
+	if updated.IsZero() {
+		updated = created
+		if len(messages) > 0 {
+			updated = messages[len(messages)-1].Timestamp
+		}
+	}
+
+	return &session.Session{
+		Version:   session.CurrentVersion,
+		ID:        session.StableID(created, string(format)+":"+sourceID),
+		Title:     title,
+		CreatedAt: created,
+		UpdatedAt: updated,
+		Model:     model,
+		Messages:  messages,
+		Tree:      tree,
+		Source:    string(format),
+	}
+}
+
+// LocalChanges reports whether stored, a session written by an earlier import,
+// has messages, pins or summaries that imported lacks, e.g. because the
+// conversation was continued in chat-tui. Importing over it would lose them.
+func LocalChanges(stored, imported *session.Session) bool {
+	type key struct {
+		role, content, summary string
+		pinned                 bool
+	}
+	// Timestamps are left out: messages without one are stamped at import
+	keyOf := func(msg session.Message) key {
+		return key{msg.Role, msg.Content, msg.Summary, msg.Pinned}
+	}
+
+	available := map[key]int{}
+	for _, n := range imported.Tree.Nodes {
+		available[keyOf(n.Message)]++
+	}
+	for _, n := range stored.Tree.Nodes {
+		k := keyOf(n.Message)
+		if available[k] == 0 {
+			return true
+		}
+		available[k]--
+	}
+	return false
+}
+
+// unixTime converts a timestamp in seconds, milliseconds or microseconds
+func unixTime(ts float64) time.Time {
+	switch {
+	case ts <= 0:
+		return time.Time{}
+	case ts > 1e14:
+		return time.UnixMicro(int64(ts))
+	case ts > 1e11:
+		return time.UnixMilli(int64(ts))
+	default:
+		sec := int64(ts)
+		return time.Unix(sec, int64((ts-float64(sec))*1e9))
+	}
+}
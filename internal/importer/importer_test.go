@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// parseFixture parses a file of testdata
+func parseFixture(t *testing.T, name string, opts Options) (Format, []*session.Session) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	format, sessions, err := Parse(data, opts)
+	if err != nil {
+		t.Fatalf("Parse(%s) error: %v", name, err)
+	}
+	return format, sessions
+}
+
+// transcript returns the active path as "role: content" lines
+func transcript(s *session.Session) []string {
+	var lines []string
+	for _, msg := range s.Tree.Messages() {
+		lines = append(lines, msg.Role+": "+msg.Content)
+	}
+	return lines
+}
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		mainOnly bool
+		format   Format
+		sessions int
+		index    int
+		title    string
+		model    string
+		created  time.Time
+		nodes    int
+		path     []string
+	}{
+		{
+			fixture:  "chatgpt.json",
+			format:   FormatChatGPT,
+			sessions: 1,
+			title:    "Sorting in Go",
+			model:    "gpt-4o",
+			created:  time.Unix(1767225600, 5e8),
+			nodes:    4,
+			path: []string{
+				"user: How do I sort a slice?",
+				"assistant: Use slices.Sort, let me check.",
+				"assistant: Sorted: [1 2 3]",
+			},
+		},
+		{
+			fixture:  "chatgpt.json",
+			mainOnly: true,
+			format:   FormatChatGPT,
+			sessions: 1,
+			title:    "Sorting in Go",
+			model:    "gpt-4o",
+			created:  time.Unix(1767225600, 5e8),
+			nodes:    3,
+			path: []string{
+				"user: How do I sort a slice?",
+				"assistant: Use slices.Sort, let me check.",
+				"assistant: Sorted: [1 2 3]",
+			},
+		},
+		{
+			fixture:  "openwebui.json",
+			format:   FormatOpenWebUI,
+			sessions: 2,
+			title:    "Haiku",
+			model:    "llama3.1:8b",
+			created:  time.Unix(1767225600, 0),
+			nodes:    3,
+			path: []string{
+				"user: Write a haiku",
+				"assistant: An old silent pond",
+			},
+		},
+		{
+			fixture:  "openwebui.json",
+			format:   FormatOpenWebUI,
+			sessions: 2,
+			index:    1,
+			title:    "Legacy",
+			model:    "mistral",
+			created:  time.Unix(1767225700, 0),
+			nodes:    2,
+			path: []string{
+				"user: Hi",
+				"assistant: Hello!",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture+"/"+tt.title, func(t *testing.T) {
+			format, sessions := parseFixture(t, tt.fixture, Options{MainBranchOnly: tt.mainOnly})
+			if format != tt.format {
+				t.Errorf("format = %s, want %s", format, tt.format)
+			}
+			// Conversations without messages are skipped
+			if len(sessions) != tt.sessions {
+				t.Fatalf("got %d sessions, want %d", len(sessions), tt.sessions)
+			}
+
+			s := sessions[tt.index]
+			if s.Title != tt.title || s.Model != tt.model || s.Source != string(tt.format) {
+				t.Errorf("title, model, source = %q, %q, %q, want %q, %q, %q", s.Title, s.Model, s.Source, tt.title, tt.model, tt.format)
+			}
+			if !s.CreatedAt.Equal(tt.created) {
+				t.Errorf("created = %v, want %v", s.CreatedAt, tt.created)
+			}
+			if s.Tree.Len() != tt.nodes {
+				t.Errorf("tree has %d nodes, want %d", s.Tree.Len(), tt.nodes)
+			}
+			if err := s.Tree.Validate(); err != nil {
+				t.Errorf("invalid tree: %v", err)
+			}
+			if got := transcript(s); !reflect.DeepEqual(got, tt.path) {
+				t.Errorf("active path = %q, want %q", got, tt.path)
+			}
+		})
+	}
+}
+
+func TestParseStableIDs(t *testing.T) {
+	for _, fixture := range []string{"chatgpt.json", "openwebui.json"} {
+		_, first := parseFixture(t, fixture, Options{})
+		_, second := parseFixture(t, fixture, Options{})
+		for i := range first {
+			if first[i].ID != second[i].ID {
+				t.Errorf("%s: session %d has ID %s, then %s", fixture, i, first[i].ID, second[i].ID)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not JSON", "conversations"},
+		{"empty list", "[]"},
+		{"unknown format", `[{"foo": 1}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Parse([]byte(tt.data), Options{}); err == nil {
+				t.Errorf("Parse(%s) succeeded, want an error", tt.data)
+			}
+		})
+	}
+}
+
+func TestLocalChanges(t *testing.T) {
+	_, sessions := parseFixture(t, "openwebui.json", Options{})
+	imported := sessions[1]
+
+	tests := []struct {
+		name   string
+		change func(s *session.Session)
+		want   bool
+	}{
+		{"unchanged", func(s *session.Session) {}, false},
+		{"retitled", func(s *session.Session) { s.Title = "Greeting" }, false},
+		{"continued", func(s *session.Session) { s.Tree.Append(session.NewMessage("user", "Bye")) }, true},
+		{"pinned", func(s *session.Session) { s.Tree.Node(s.Tree.Leaf()).Message.Pinned = true }, true},
+		{"message removed", func(s *session.Session) { s.Tree.Remove(s.Tree.Leaf()) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := *imported
+			stored.Tree = imported.Tree.Clone()
+			tt.change(&stored)
+			if got := LocalChanges(&stored, imported); got != tt.want {
+				t.Errorf("LocalChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
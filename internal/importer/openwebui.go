@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// openWebUIExport is one chat of an Open WebUI export; the chat is either
+// wrapped with its metadata or exported bare
+type openWebUIExport struct {
+	ID        string         `json:"id"`
+	Title     string         `json:"title"`
+	CreatedAt float64        `json:"created_at"`
+	UpdatedAt float64        `json:"updated_at"`
+	Chat      *openWebUIChat `json:"chat"`
+}
+
+type openWebUIChat struct {
+	ID        string             `json:"id"`
+	Title     string             `json:"title"`
+	Models    []string           `json:"models"`
+	Timestamp float64            `json:"timestamp"`
+	Messages  []openWebUIMessage `json:"messages"`
+	History   *struct {
+		Messages  map[string]openWebUIMessage `json:"messages"`
+		CurrentID string                      `json:"currentId"`
+	} `json:"history"`
+}
+
+type openWebUIMessage struct {
+	ID          string   `json:"id"`
+	ParentID    *string  `json:"parentId"`
+	ChildrenIDs []string `json:"childrenIds"`
+	Role        string   `json:"role"`
+	Content     string   `json:"content"`
+	Model       string   `json:"model"`
+	Timestamp   float64  `json:"timestamp"`
+}
+
+// parseOpenWebUI converts Open WebUI chats
+func parseOpenWebUI(items []json.RawMessage, opts Options) ([]*session.Session, error) {
+	sessions := make([]*session.Session, 0, len(items))
+	for i, item := range items {
+		var export openWebUIExport
+		if err := json.Unmarshal(item, &export); err != nil {
+			return nil, fmt.Errorf("failed to parse chat %d: %w", i+1, err)
+		}
+		chat := export.Chat
+		if chat == nil {
+			chat = &openWebUIChat{}
+			if err := json.Unmarshal(item, chat); err != nil {
+				return nil, fmt.Errorf("failed to parse chat %d: %w", i+1, err)
+			}
+		}
+
+		model := ""
+		if len(chat.Models) > 0 {
+			model = chat.Models[0]
+		}
+
+		// The history tree holds every branch; older exports only have the
+		// linear message list
+		nodes := map[string]*node{}
+		current := ""
+		if chat.History != nil && len(chat.History.Messages) > 0 {
+			for id, msg := range chat.History.Messages {
+				n := &node{children: msg.ChildrenIDs, message: convertOpenWebUIMessage(msg)}
+				if msg.ParentID != nil {
+					n.parent = *msg.ParentID
+				}
+				if model == "" && msg.Role == "assistant" {
+					model = msg.Model
+				}
+				nodes[id] = n
+			}
+			current = chat.History.CurrentID
+		} else {
+			parent := ""
+			for j, msg := range chat.Messages {
+				id := fmt.Sprintf("%d", j)
+				nodes[id] = &node{parent: parent, message: convertOpenWebUIMessage(msg)}
+				if parent != "" {
+					nodes[parent].children = []string{id}
+				}
+				parent, current = id, id
+			}
+		}
+		tree := buildTree(nodes, current, opts.MainBranchOnly)
+		if tree.Len() == 0 {
+			continue
+		}
+
+		title := export.Title
+		if title == "" {
+			title = chat.Title
+		}
+		sourceID := export.ID
+		if sourceID == "" {
+			sourceID = chat.ID
+		}
+		if sourceID == "" {
+			sourceID = title + current
+		}
+		created := unixTime(export.CreatedAt)
+		if created.IsZero() {
+			created = unixTime(chat.Timestamp)
+		}
+		sessions = append(sessions, newSession(FormatOpenWebUI, sourceID, title, model,
+			created, unixTime(export.UpdatedAt), tree))
+	}
+	return sessions, nil
+}
+
+// convertOpenWebUIMessage returns the chat message of an Open WebUI message,
+// or nil if it has no text
+func convertOpenWebUIMessage(m openWebUIMessage) *session.Message {
+	switch m.Role {
+	case "user", "assistant", "system":
+	default:
+		return nil
+	}
+	if strings.TrimSpace(m.Content) == "" {
+		return nil
+	}
+
+	msg := session.NewMessage(m.Role, m.Content)
+	if ts := unixTime(m.Timestamp); !ts.IsZero() {
+		msg.Timestamp = ts
+	}
+	return &msg
+}
@@ -0,0 +1,353 @@
+package keymap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Groups of actions. Keys only conflict within a group, or with the scroll
+// keys, which work in every mode.
+const (
+	GroupChat      = "Chat"
+	GroupStreaming = "Streaming"
+	GroupScroll    = "Full-screen scrolling"
+	GroupSelection = "Message selection"
+	GroupPicker    = "Pickers"
+	GroupConfirm   = "Confirmations"
+	GroupCompare   = "Compare"
+)
+
+// groupOrder is the order of the groups in the help overlay
+var groupOrder = []string{GroupChat, GroupStreaming, GroupScroll, GroupSelection, GroupPicker, GroupConfirm, GroupCompare}
+
+// KeyMap holds the key bindings of every action
+type KeyMap struct {
+	// Chat
+	Send       key.Binding
+	Quit       key.Binding
+	Help       key.Binding
+	Multiline  key.Binding
+	Stats      key.Binding
+	StatsPanel key.Binding
+	CodeFocus  key.Binding
+	Complete   key.Binding
+	Prev       key.Binding
+	Next       key.Binding
+	Select     key.Binding
+	Undo       key.Binding
+	Redo       key.Binding
+	BranchPrev key.Binding
+	BranchNext key.Binding
+	BranchUp   key.Binding
+	BranchDown key.Binding
+
+	// Streaming
+	Cancel key.Binding
+
+	// Full-screen scrolling
+	PageUp       key.Binding
+	PageDown     key.Binding
+	ScrollUp     key.Binding
+	ScrollDown   key.Binding
+	ScrollTop    key.Binding
+	ScrollBottom key.Binding
+
+	// Message selection
+	SelectUp     key.Binding
+	SelectDown   key.Binding
+	SelectFirst  key.Binding
+	SelectLast   key.Binding
+	CopyText     key.Binding
+	CopyMarkdown key.Binding
+	Delete       key.Binding
+	Edit         key.Binding
+	Pin          key.Binding
+	Fork         key.Binding
+	MessageStats key.Binding
+	SelectDone   key.Binding
+
+	// Pickers
+	PickerUp    key.Binding
+	PickerDown  key.Binding
+	PickerPick  key.Binding
+	PickerClose key.Binding
+
+	// Confirmations
+	Confirm key.Binding
+
+	// Compare
+	ComparePick key.Binding // the n-th key picks column n
+}
+
+// Action is a rebindable action, named as in the keys: config section
+type Action struct {
+	Name    string
+	Group   string
+	Binding *key.Binding
+}
+
+// Actions returns every action of the key map in help order
+func (k *KeyMap) Actions() []Action {
+	return []Action{
+		{"send", GroupChat, &k.Send},
+		{"quit", GroupChat, &k.Quit},
+		{"help", GroupChat, &k.Help},
+		{"multiline", GroupChat, &k.Multiline},
+		{"stats", GroupChat, &k.Stats},
+		{"stats_panel", GroupChat, &k.StatsPanel},
+		{"code_focus", GroupChat, &k.CodeFocus},
+		{"complete", GroupChat, &k.Complete},
+		{"prev", GroupChat, &k.Prev},
+		{"next", GroupChat, &k.Next},
+		{"select", GroupChat, &k.Select},
+		{"undo", GroupChat, &k.Undo},
+		{"redo", GroupChat, &k.Redo},
+		{"branch_prev", GroupChat, &k.BranchPrev},
+		{"branch_next", GroupChat, &k.BranchNext},
+		{"branch_up", GroupChat, &k.BranchUp},
+		{"branch_down", GroupChat, &k.BranchDown},
+
+		{"cancel", GroupStreaming, &k.Cancel},
+
+		{"page_up", GroupScroll, &k.PageUp},
+		{"page_down", GroupScroll, &k.PageDown},
+		{"scroll_up", GroupScroll, &k.ScrollUp},
+		{"scroll_down", GroupScroll, &k.ScrollDown},
+		{"scroll_top", GroupScroll, &k.ScrollTop},
+		{"scroll_bottom", GroupScroll, &k.ScrollBottom},
+
+		{"select_up", GroupSelection, &k.SelectUp},
+		{"select_down", GroupSelection, &k.SelectDown},
+		{"select_first", GroupSelection, &k.SelectFirst},
+		{"select_last", GroupSelection, &k.SelectLast},
+		{"copy_text", GroupSelection, &k.CopyText},
+		{"copy_markdown", GroupSelection, &k.CopyMarkdown},
+		{"delete", GroupSelection, &k.Delete},
+		{"edit", GroupSelection, &k.Edit},
+		{"pin", GroupSelection, &k.Pin},
+		{"fork", GroupSelection, &k.Fork},
+		{"message_stats", GroupSelection, &k.MessageStats},
+		{"select_done", GroupSelection, &k.SelectDone},
+
+		{"picker_up", GroupPicker, &k.PickerUp},
+		{"picker_down", GroupPicker, &k.PickerDown},
+		{"picker_pick", GroupPicker, &k.PickerPick},
+		{"picker_close", GroupPicker, &k.PickerClose},
+
+		{"confirm", GroupConfirm, &k.Confirm},
+
+		{"compare_pick", GroupCompare, &k.ComparePick},
+	}
+}
+
+// binding creates a binding whose help shows its keys
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(Describe(keys), desc))
+}
+
+// Default returns the built-in key bindings
+func Default() *KeyMap {
+	return &KeyMap{
+		Send:       binding("send message or run command", "enter"),
+		Quit:       binding("quit", "ctrl+c"),
+		Help:       binding("show key bindings", "f1"),
+		Multiline:  binding("toggle multiline mode", "ctrl+d"),
+		Stats:      binding("toggle stats", "ctrl+s"),
+		StatsPanel: binding("toggle stats pane", "alt+s"),
+		CodeFocus:  binding("focus next code block", "ctrl+o"),
+		Complete:   binding("complete command", "tab"),
+		Prev:       binding("previous suggestion or input", "up"),
+		Next:       binding("next suggestion or input", "down"),
+		Select:     binding("select messages (empty input)", "esc"),
+		Undo:       binding("undo", "ctrl+z"),
+		Redo:       binding("redo", "ctrl+y"),
+		BranchPrev: binding("previous branch", "ctrl+left"),
+		BranchNext: binding("next branch", "ctrl+right"),
+		BranchUp:   binding("select earlier branching turn", "alt+up"),
+		BranchDown: binding("select later branching turn", "alt+down"),
+
+		Cancel: binding("cancel the reply", "ctrl+c"),
+
+		PageUp:       binding("page up", "pgup"),
+		PageDown:     binding("page down", "pgdown"),
+		ScrollUp:     binding("scroll up", "ctrl+up"),
+		ScrollDown:   binding("scroll down", "ctrl+down"),
+		ScrollTop:    binding("scroll to top", "ctrl+home"),
+		ScrollBottom: binding("follow", "ctrl+end"),
+
+		SelectUp:     binding("up", "up", "k"),
+		SelectDown:   binding("down", "down", "j"),
+		SelectFirst:  binding("first", "home", "g"),
+		SelectLast:   binding("last", "end", "G"),
+		CopyText:     binding("copy", "c"),
+		CopyMarkdown: binding("copy markdown", "y"),
+		Delete:       binding("delete", "d"),
+		Edit:         binding("edit", "e"),
+		Pin:          binding("pin", "p"),
+		Fork:         binding("fork from here", "f"),
+		MessageStats: binding("stats", "s"),
+		SelectDone:   binding("done", "esc", "q"),
+
+		PickerUp:    binding("up", "up"),
+		PickerDown:  binding("down", "down"),
+		PickerPick:  binding("open", "enter"),
+		PickerClose: binding("close", "esc", "ctrl+c"),
+
+		Confirm: binding("yes (any other key cancels)", "y", "Y"),
+
+		ComparePick: binding("continue with the answer of the n-th column", "1", "2", "3"),
+	}
+}
+
+// New returns the default bindings with the actions in overrides rebound. An
+// empty key list disables an action. Unknown actions, invalid keys and keys
+// bound to two actions are reported together in the error; the returned key
+// map is usable either way, ignoring the invalid entries.
+func New(overrides map[string][]string) (*KeyMap, error) {
+	k := Default()
+	actions := map[string]Action{}
+	for _, a := range k.Actions() {
+		actions[a.Name] = a
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		action, ok := actions[strings.ToLower(name)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key action %q", name))
+			continue
+		}
+
+		var keys []string
+		valid := true
+		for _, raw := range overrides[name] {
+			parsed, err := parseKey(raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", action.Name, err))
+				valid = false
+				continue
+			}
+			keys = append(keys, parsed)
+		}
+		if !valid {
+			continue
+		}
+
+		if len(keys) == 0 {
+			action.Binding.Unbind()
+			continue
+		}
+		action.Binding.SetKeys(keys...)
+		action.Binding.SetHelp(Describe(keys), action.Binding.Help().Desc)
+	}
+
+	errs = append(errs, k.conflicts()...)
+	return k, errors.Join(errs...)
+}
+
+// conflicts reports keys bound to two actions that are active at the same time
+func (k *KeyMap) conflicts() []error {
+	var errs []error
+	seen := map[string]map[string]string{} // group -> key -> action
+	for _, group := range groupOrder {
+		seen[group] = map[string]string{}
+	}
+
+	for _, a := range k.Actions() {
+		for _, bound := range a.Binding.Keys() {
+			for _, group := range groupOrder {
+				// Scroll keys are checked before every other group
+				if group != a.Group && group != GroupScroll && a.Group != GroupScroll {
+					continue
+				}
+				if other, ok := seen[group][bound]; ok && other != a.Name {
+					errs = append(errs, fmt.Errorf("%q is bound to both %s and %s", bound, other, a.Name))
+				}
+			}
+			seen[a.Group][bound] = a.Name
+		}
+	}
+	return errs
+}
+
+// Group is a titled set of actions in the help overlay
+type Group struct {
+	Title   string
+	Actions []Action
+}
+
+// Groups returns the bound actions of each group, for the help overlay
+func (k *KeyMap) Groups() []Group {
+	groups := make([]Group, len(groupOrder))
+	for i, title := range groupOrder {
+		groups[i].Title = title
+		for _, a := range k.Actions() {
+			if a.Group == title && a.Binding.Enabled() {
+				groups[i].Actions = append(groups[i].Actions, a)
+			}
+		}
+	}
+	return groups
+}
+
+// Describe renders keys for help text, e.g. "ctrl+←/ctrl+→"
+func Describe(keys []string) string {
+	described := make([]string, len(keys))
+	for i, k := range keys {
+		described[i] = describeKey(k)
+	}
+	return strings.Join(described, "/")
+}
+
+// arrows replaces the names of arrow keys in help text
+var arrows = strings.NewReplacer("left", "←", "right", "→", "up", "↑", "down", "↓")
+
+// describeKey shows arrow keys as arrows and the space bar by name
+func describeKey(k string) string {
+	if k == " " {
+		return "space"
+	}
+	if strings.HasSuffix(k, "pgup") || strings.HasSuffix(k, "pgdown") {
+		return k
+	}
+	return arrows.Replace(k)
+}
+
+// keyNames holds the names Bubble Tea gives to special keys
+var keyNames = func() map[string]bool {
+	names := map[string]bool{}
+	for t := tea.KeyType(-128); t < 128; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// parseKey validates a configured key, e.g. "ctrl+s", "alt+up", "q" or
+// "space", and returns it as Bubble Tea names it
+func parseKey(raw string) (string, error) {
+	k := strings.TrimSpace(raw)
+	base, alt := strings.CutPrefix(k, "alt+")
+	if base == "space" {
+		base = " "
+	}
+	if !keyNames[base] && len([]rune(base)) != 1 {
+		return "", fmt.Errorf("invalid key %q", raw)
+	}
+	if alt {
+		return "alt+" + base, nil
+	}
+	return base, nil
+}
@@ -20,20 +20,37 @@ type StreamChunk struct {
 
 // RequestStats tracks statistics for a request
 type RequestStats struct {
-	StartTime            time.Time
-	EndTime              time.Time
-	FirstTokenTime       time.Time
-	Model                string
-	InputTokens          int
-	OutputTokens         int
-	TotalTokens          int
-	TokensPerSec         float64
-	TimeToFirstToken     time.Duration
-	GenerationTime       time.Duration
-	PostFirstTokenSpeed  float64
-	Latency              time.Duration
-	HTTPStatus           int
-	CostEstimate         float64
+	StartTime           time.Time     `json:"start_time"`
+	EndTime             time.Time     `json:"end_time"`
+	FirstTokenTime      time.Time     `json:"first_token_time"`
+	Model               string        `json:"model"`
+	InputTokens         int           `json:"input_tokens"`
+	OutputTokens        int           `json:"output_tokens"`
+	TotalTokens         int           `json:"total_tokens"`
+	TokensPerSec        float64       `json:"tokens_per_sec"`
+	TimeToFirstToken    time.Duration `json:"time_to_first_token"`
+	GenerationTime      time.Duration `json:"generation_time"`
+	PostFirstTokenSpeed float64       `json:"post_first_token_speed"`
+	Latency             time.Duration `json:"latency"`
+	HTTPStatus          int           `json:"http_status"`
+	CostEstimate        float64       `json:"cost_estimate"`
+}
+
+// MessageOverhead approximates the per-message tokens added by the chat format
+const MessageOverhead = 4
+
+// EstimateTokens approximates the token count of text (about 4 characters per token)
+func EstimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
+
+// EstimatePromptTokens approximates the input tokens of a request
+func EstimatePromptTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += EstimateTokens(msg.Content) + MessageOverhead
+	}
+	return total
 }
 
 // Client defines the interface for LLM API clients
@@ -7,8 +7,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,6 +22,9 @@ type OpenAIClient struct {
 	temperature float64
 	maxTokens   int
 	httpClient  *http.Client
+	logger      *slog.Logger
+	// noStreamUsage is set once the server rejected stream_options
+	noStreamUsage atomic.Bool
 }
 
 // NewOpenAIClient creates a new OpenAI-compatible client
@@ -33,9 +38,64 @@ func NewOpenAIClient(apiKey, baseURL, model string, temperature float64, maxToke
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
+		logger: slog.New(slog.DiscardHandler),
 	}
 }
 
+// SetLogger sets the logger used for request/response debug logging
+func (c *OpenAIClient) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	c.logger = logger
+}
+
+// logRequest logs an outgoing request; the body and headers are only logged at debug level
+func (c *OpenAIClient) logRequest(ctx context.Context, req *http.Request, body []byte) {
+	c.logger.InfoContext(ctx, "request",
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.String("model", c.model),
+	)
+	c.logger.DebugContext(ctx, "request body",
+		headerAttr("headers", req.Header),
+		slog.String("body", string(body)),
+	)
+}
+
+// logResponse logs the response status and headers
+func (c *OpenAIClient) logResponse(ctx context.Context, resp *http.Response, elapsed time.Duration) {
+	c.logger.InfoContext(ctx, "response",
+		slog.Int("status", resp.StatusCode),
+		slog.Duration("elapsed", elapsed),
+	)
+	c.logger.DebugContext(ctx, "response headers",
+		headerAttr("headers", resp.Header),
+	)
+}
+
+// logStats logs the timings of a completed request
+func (c *OpenAIClient) logStats(ctx context.Context, stats *RequestStats) {
+	c.logger.InfoContext(ctx, "complete",
+		slog.String("model", stats.Model),
+		slog.Duration("latency", stats.Latency),
+		slog.Duration("ttft", stats.TimeToFirstToken),
+		slog.Duration("generation_time", stats.GenerationTime),
+		slog.Int("input_tokens", stats.InputTokens),
+		slog.Int("output_tokens", stats.OutputTokens),
+		slog.Float64("tokens_per_sec", stats.TokensPerSec),
+	)
+}
+
+// headerAttr converts HTTP headers into a group so secrets can be redacted per key
+func headerAttr(key string, h http.Header) slog.Attr {
+	attrs := make([]any, 0, len(h))
+	for name, values := range h {
+		attrs = append(attrs, slog.String(name, strings.Join(values, ", ")))
+	}
+	return slog.Group(key, attrs...)
+}
+
 // Chat sends a non-streaming chat request
 func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, *RequestStats, error) {
 	stats := &RequestStats{
@@ -64,8 +124,11 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, *R
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 
+	c.logRequest(ctx, req, jsonData)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.logger.ErrorContext(ctx, "request failed", slog.String("error", err.Error()))
 		return "", stats, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
@@ -73,11 +136,13 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, *R
 	stats.HTTPStatus = resp.StatusCode
 	stats.EndTime = time.Now()
 	stats.Latency = stats.EndTime.Sub(stats.StartTime)
+	c.logResponse(ctx, resp, stats.Latency)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", stats, fmt.Errorf("failed to read response: %w", err)
 	}
+	c.logger.DebugContext(ctx, "response body", slog.String("body", string(body)))
 
 	if resp.StatusCode != http.StatusOK {
 		return "", stats, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
@@ -111,15 +176,67 @@ func (c *OpenAIClient) Chat(ctx context.Context, messages []Message) (string, *R
 	if stats.OutputTokens > 0 && stats.Latency > 0 {
 		stats.TokensPerSec = float64(stats.OutputTokens) / stats.Latency.Seconds()
 	}
+	c.logStats(ctx, stats)
 
 	return result.Choices[0].Message.Content, stats, nil
 }
 
+// postStream sends a streaming chat request and records its status in stats
+func (c *OpenAIClient) postStream(ctx context.Context, reqBody map[string]interface{}, stats *RequestStats) (*http.Response, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "text/event-stream")
+
+	c.logRequest(ctx, req, jsonData)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "request failed", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	stats.HTTPStatus = resp.StatusCode
+	c.logResponse(ctx, resp, time.Since(stats.StartTime))
+	return resp, nil
+}
+
+// readError reads and closes the body of a failed response and returns it as an error
+func (c *OpenAIClient) readError(ctx context.Context, resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	c.logger.DebugContext(ctx, "response body", slog.String("body", string(body)))
+	return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+}
+
+// rejectsStreamOptions reports whether an API error is about stream_options
+func rejectsStreamOptions(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "stream_options") || strings.Contains(msg, "include_usage")
+}
+
+// streamUsage is the token usage reported in the last chunk of a stream
+type streamUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
 // ChatStream sends a streaming chat request
 func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-chan StreamChunk, *RequestStats, error) {
 	stats := &RequestStats{
 		StartTime: time.Now(),
 		Model:     c.model,
+		// Estimated until the server reports usage
+		InputTokens: EstimatePromptTokens(messages),
 	}
 
 	reqBody := map[string]interface{}{
@@ -129,32 +246,33 @@ func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-ch
 		"max_tokens":  c.maxTokens,
 		"stream":      true,
 	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, stats, fmt.Errorf("failed to marshal request: %w", err)
+	withUsage := !c.noStreamUsage.Load()
+	if withUsage {
+		reqBody["stream_options"] = map[string]interface{}{
+			"include_usage": true,
+		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	resp, err := c.postStream(ctx, reqBody, stats)
 	if err != nil {
-		return nil, stats, fmt.Errorf("failed to create request: %w", err)
+		return nil, stats, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Accept", "text/event-stream")
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, stats, fmt.Errorf("failed to send request: %w", err)
-	}
-
-	stats.HTTPStatus = resp.StatusCode
-
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, stats, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		err := c.readError(ctx, resp)
+		// Some OpenAI-compatible servers reject stream_options. Retry once
+		// without it, and leave it out from then on if that works.
+		if resp.StatusCode != http.StatusBadRequest || !withUsage || !rejectsStreamOptions(err) {
+			return nil, stats, err
+		}
+		delete(reqBody, "stream_options")
+		if resp, err = c.postStream(ctx, reqBody, stats); err != nil {
+			return nil, stats, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, stats, c.readError(ctx, resp)
+		}
+		c.noStreamUsage.Store(true)
 	}
 
 	chunks := make(chan StreamChunk, 10)
@@ -163,33 +281,62 @@ func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-ch
 		defer resp.Body.Close()
 		defer close(chunks)
 
+		// send delivers a chunk unless the caller cancelled the request
+		send := func(chunk StreamChunk) bool {
+			select {
+			case chunks <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
 		reader := bufio.NewReader(resp.Body)
 		tokenCount := 0
 		firstTokenReceived := false
+		var usage *streamUsage
+
+		// finish completes the stats once the stream ended
+		finish := func() {
+			stats.EndTime = time.Now()
+			stats.Latency = stats.EndTime.Sub(stats.StartTime)
+			stats.OutputTokens = tokenCount
+
+			// Prefer the token counts reported by the server
+			if usage != nil {
+				if usage.PromptTokens > 0 {
+					stats.InputTokens = usage.PromptTokens
+				}
+				if usage.CompletionTokens > 0 {
+					stats.OutputTokens = usage.CompletionTokens
+				}
+			}
+			stats.TotalTokens = stats.InputTokens + stats.OutputTokens
+
+			// Calculate generation time and post-first-token speed
+			if firstTokenReceived {
+				stats.GenerationTime = stats.EndTime.Sub(stats.FirstTokenTime)
+				if stats.OutputTokens > 1 && stats.GenerationTime > 0 {
+					stats.PostFirstTokenSpeed = float64(stats.OutputTokens-1) / stats.GenerationTime.Seconds()
+				}
+			}
+
+			// Calculate overall tokens per second
+			if stats.OutputTokens > 0 && stats.Latency > 0 {
+				stats.TokensPerSec = float64(stats.OutputTokens) / stats.Latency.Seconds()
+			}
+			c.logStats(ctx, stats)
+		}
 
 		for {
 			line, err := reader.ReadBytes('\n')
 			if err != nil {
 				if err != io.EOF {
-					chunks <- StreamChunk{Error: fmt.Errorf("stream read error: %w", err)}
-				}
-				stats.EndTime = time.Now()
-				stats.Latency = stats.EndTime.Sub(stats.StartTime)
-				stats.OutputTokens = tokenCount
-
-				// Calculate generation time and post-first-token speed
-				if firstTokenReceived {
-					stats.GenerationTime = stats.EndTime.Sub(stats.FirstTokenTime)
-					if tokenCount > 1 && stats.GenerationTime > 0 {
-						stats.PostFirstTokenSpeed = float64(tokenCount-1) / stats.GenerationTime.Seconds()
-					}
+					c.logger.ErrorContext(ctx, "stream read error", slog.String("error", err.Error()))
+					send(StreamChunk{Error: fmt.Errorf("stream read error: %w", err)})
 				}
-
-				// Calculate overall tokens per second
-				if tokenCount > 0 && stats.Latency > 0 {
-					stats.TokensPerSec = float64(tokenCount) / stats.Latency.Seconds()
-				}
-				chunks <- StreamChunk{Done: true}
+				finish()
+				send(StreamChunk{Done: true})
 				return
 			}
 
@@ -197,6 +344,10 @@ func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-ch
 			if len(line) == 0 {
 				continue
 			}
+			c.logger.DebugContext(ctx, "sse frame",
+				slog.Duration("elapsed", time.Since(stats.StartTime)),
+				slog.String("data", string(line)),
+			)
 
 			// SSE format: "data: {...}"
 			if !bytes.HasPrefix(line, []byte("data: ")) {
@@ -207,23 +358,8 @@ func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-ch
 
 			// Check for stream end marker
 			if bytes.Equal(data, []byte("[DONE]")) {
-				stats.EndTime = time.Now()
-				stats.Latency = stats.EndTime.Sub(stats.StartTime)
-				stats.OutputTokens = tokenCount
-
-				// Calculate generation time and post-first-token speed
-				if firstTokenReceived {
-					stats.GenerationTime = stats.EndTime.Sub(stats.FirstTokenTime)
-					if tokenCount > 1 && stats.GenerationTime > 0 {
-						stats.PostFirstTokenSpeed = float64(tokenCount-1) / stats.GenerationTime.Seconds()
-					}
-				}
-
-				// Calculate overall tokens per second
-				if tokenCount > 0 && stats.Latency > 0 {
-					stats.TokensPerSec = float64(tokenCount) / stats.Latency.Seconds()
-				}
-				chunks <- StreamChunk{Done: true}
+				finish()
+				send(StreamChunk{Done: true})
 				return
 			}
 
@@ -233,11 +369,15 @@ func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-ch
 						Content string `json:"content"`
 					} `json:"delta"`
 				} `json:"choices"`
+				Usage *streamUsage `json:"usage"`
 			}
 
 			if err := json.Unmarshal(data, &streamResp); err != nil {
 				continue
 			}
+			if streamResp.Usage != nil {
+				usage = streamResp.Usage
+			}
 
 			if len(streamResp.Choices) > 0 && streamResp.Choices[0].Delta.Content != "" {
 				content := streamResp.Choices[0].Delta.Content
@@ -250,7 +390,9 @@ func (c *OpenAIClient) ChatStream(ctx context.Context, messages []Message) (<-ch
 					firstTokenReceived = true
 				}
 
-				chunks <- StreamChunk{Content: content, Done: false}
+				if !send(StreamChunk{Content: content, Done: false}) {
+					return
+				}
 			}
 		}
 	}()
@@ -0,0 +1,241 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// indexVersion is bumped whenever the on-disk index layout changes
+const indexVersion = 2
+
+// indexFile is the index file name inside the session directory
+const indexFile = ".search-index"
+
+// Posting records where a term occurs in one message, identified by its node
+// in the conversation tree
+type Posting struct {
+	Session   string `json:"s"`
+	Node      int    `json:"n"`
+	Positions []int  `json:"p"`
+}
+
+// MessageEntry holds the per-message fields used by filters
+type MessageEntry struct {
+	Role      string    `json:"role"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SessionEntry holds the indexed metadata of one session
+type SessionEntry struct {
+	ModTime  time.Time            `json:"mod_time"`
+	Title    string               `json:"title"`
+	Model    string               `json:"model"`
+	Messages map[int]MessageEntry `json:"messages"`
+	Terms    []string             `json:"terms"`
+}
+
+// Index is an inverted index over all stored sessions. Sync, AddSaved and
+// Find may run concurrently; the other methods may not.
+type Index struct {
+	Version  int                      `json:"version"`
+	Sessions map[string]*SessionEntry `json:"sessions"`
+	Postings map[string][]Posting     `json:"postings"`
+
+	mu    sync.Mutex
+	path  string
+	dirty bool
+}
+
+// Open loads the index kept in the store directory, or starts an empty one
+func Open(store *session.Store) (*Index, error) {
+	idx := &Index{
+		Version:  indexVersion,
+		Sessions: map[string]*SessionEntry{},
+		Postings: map[string][]Posting{},
+		path:     filepath.Join(store.Dir(), indexFile),
+	}
+
+	data, err := os.ReadFile(idx.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+
+	var saved Index
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Version != indexVersion {
+		// Rebuild from scratch on a corrupt or outdated index
+		return idx, nil
+	}
+	if saved.Sessions != nil {
+		idx.Sessions = saved.Sessions
+	}
+	if saved.Postings != nil {
+		idx.Postings = saved.Postings
+	}
+
+	return idx, nil
+}
+
+// Sync brings the index up to date with the store, re-indexing only sessions
+// that were added, changed or removed since the last sync
+func (idx *Index) Sync(store *session.Store) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.sync(store)
+}
+
+// sync is Sync without the lock
+func (idx *Index) sync(store *session.Store) error {
+	modTimes, err := store.ModTimes()
+	if err != nil {
+		return err
+	}
+
+	for id := range idx.Sessions {
+		if _, ok := modTimes[id]; !ok {
+			idx.Remove(id)
+		}
+	}
+
+	for id, modTime := range modTimes {
+		if entry, ok := idx.Sessions[id]; ok && entry.ModTime.Equal(modTime) {
+			continue
+		}
+
+		s, err := store.Load(id)
+		if err != nil {
+			// Leave unreadable files out of the index
+			idx.Remove(id)
+			continue
+		}
+		idx.Add(id, s, modTime)
+	}
+
+	return idx.Save()
+}
+
+// AddSaved indexes a session just written to store
+func (idx *Index) AddSaved(store *session.Store, s *session.Session) error {
+	modTime, err := store.ModTime(s.ID)
+	if err != nil {
+		return err
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.Add(s.ID, s, modTime)
+	return idx.Save()
+}
+
+// Add indexes every message of a session, on all branches, replacing any
+// previous entry for it
+func (idx *Index) Add(id string, s *session.Session, modTime time.Time) {
+	idx.Remove(id)
+
+	entry := &SessionEntry{
+		ModTime:  modTime,
+		Title:    s.DisplayTitle(),
+		Model:    s.Model,
+		Messages: map[int]MessageEntry{},
+	}
+
+	tree := s.Tree
+	if tree == nil {
+		tree = session.FromMessages(s.Messages)
+	}
+	nodes := make([]int, 0, tree.Len())
+	for nodeID := range tree.Nodes {
+		nodes = append(nodes, nodeID)
+	}
+	sort.Ints(nodes)
+
+	terms := map[string]bool{}
+	for _, nodeID := range nodes {
+		msg := tree.Node(nodeID).Message
+		entry.Messages[nodeID] = MessageEntry{Role: msg.Role, Timestamp: msg.Timestamp}
+
+		positions := map[string][]int{}
+		for pos, token := range Tokenize(msg.Content) {
+			positions[token] = append(positions[token], pos)
+		}
+		for term, pos := range positions {
+			idx.Postings[term] = append(idx.Postings[term], Posting{Session: id, Node: nodeID, Positions: pos})
+			terms[term] = true
+		}
+	}
+
+	for term := range terms {
+		entry.Terms = append(entry.Terms, term)
+	}
+	sort.Strings(entry.Terms)
+
+	idx.Sessions[id] = entry
+	idx.dirty = true
+}
+
+// Remove drops a session from the index
+func (idx *Index) Remove(id string) {
+	entry, ok := idx.Sessions[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range entry.Terms {
+		postings := idx.Postings[term][:0]
+		for _, p := range idx.Postings[term] {
+			if p.Session != id {
+				postings = append(postings, p)
+			}
+		}
+		if len(postings) == 0 {
+			delete(idx.Postings, term)
+		} else {
+			idx.Postings[term] = postings
+		}
+	}
+
+	delete(idx.Sessions, id)
+	idx.dirty = true
+}
+
+// Save writes the index to disk if it changed
+func (idx *Index) Save() error {
+	if !idx.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+
+	idx.dirty = false
+	return nil
+}
+
+// Tokenize splits text into lowercase word tokens
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"snake_case and kebab-case", []string{"snake_case", "and", "kebab", "case"}},
+		{"v1.2 costs $30", []string{"v1", "2", "costs", "30"}},
+		{"Über naïve 日本語", []string{"über", "naïve", "日本語"}},
+		{"  \n\t ", []string{}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchAllBranches(t *testing.T) {
+	tree := session.NewTree()
+	question := tree.Append(session.NewMessage("user", "how do I deploy"))
+	first := tree.Append(session.NewMessage("assistant", "use kubernetes"))
+	second := tree.AddSibling(first, session.NewMessage("assistant", "use nomad"))
+	tree.Activate(second)
+
+	idx := &Index{Sessions: map[string]*SessionEntry{}, Postings: map[string][]Posting{}}
+	idx.Add("s1", &session.Session{Tree: tree, Messages: tree.Messages()}, time.Now())
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"deploy", question},
+		{"kubernetes", first},
+		{"nomad", second},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hits := idx.Search(q)
+		if len(hits) != 1 || hits[0].Node != tt.want {
+			t.Errorf("Search(%q) = %+v, want node %d", tt.query, hits, tt.want)
+		}
+	}
+}
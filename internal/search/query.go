@@ -0,0 +1,307 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+)
+
+// dateLayout is the format of after:/before: filters
+const dateLayout = "2006-01-02"
+
+// Query is a parsed search query
+type Query struct {
+	Terms   []string
+	Phrases [][]string
+	Role    string
+	Model   string
+	After   time.Time
+	Before  time.Time
+}
+
+// ParseQuery parses free text with "quoted phrases" and the filters
+// role:<role>, model:<name>, after:<YYYY-MM-DD> and before:<YYYY-MM-DD>
+func ParseQuery(input string) (*Query, error) {
+	q := &Query{}
+
+	for _, field := range splitQuery(input) {
+		if strings.HasPrefix(field, `"`) {
+			phrase := Tokenize(strings.Trim(field, `"`))
+			switch len(phrase) {
+			case 0:
+			case 1:
+				q.Terms = append(q.Terms, phrase[0])
+			default:
+				q.Phrases = append(q.Phrases, phrase)
+			}
+			continue
+		}
+
+		key, value, found := strings.Cut(field, ":")
+		if found && value != "" {
+			switch strings.ToLower(key) {
+			case "role":
+				q.Role = strings.ToLower(value)
+				continue
+			case "model":
+				q.Model = strings.ToLower(value)
+				continue
+			case "after":
+				t, err := time.ParseInLocation(dateLayout, value, time.Local)
+				if err != nil {
+					return nil, fmt.Errorf("invalid after: date %q (use YYYY-MM-DD)", value)
+				}
+				q.After = t
+				continue
+			case "before":
+				t, err := time.ParseInLocation(dateLayout, value, time.Local)
+				if err != nil {
+					return nil, fmt.Errorf("invalid before: date %q (use YYYY-MM-DD)", value)
+				}
+				q.Before = t
+				continue
+			}
+		}
+
+		q.Terms = append(q.Terms, Tokenize(field)...)
+	}
+
+	if len(q.Terms) == 0 && len(q.Phrases) == 0 {
+		return nil, fmt.Errorf("search query needs at least one word or phrase")
+	}
+
+	return q, nil
+}
+
+// splitQuery splits on whitespace while keeping "quoted phrases" together
+func splitQuery(input string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuote := false
+
+	flush := func() {
+		if current.Len() > 0 {
+			fields = append(fields, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			if inQuote {
+				current.WriteRune(r)
+				flush()
+			} else {
+				flush()
+				current.WriteRune(r)
+			}
+			inQuote = !inQuote
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return fields
+}
+
+// HighlightTerms returns every word that should be highlighted in snippets
+func (q *Query) HighlightTerms() []string {
+	terms := append([]string{}, q.Terms...)
+	for _, phrase := range q.Phrases {
+		terms = append(terms, phrase...)
+	}
+	return terms
+}
+
+// Hit is a message matching a query, identified by its node in the session tree
+type Hit struct {
+	Session   string
+	Node      int
+	Title     string
+	Model     string
+	Role      string
+	Timestamp time.Time
+	Score     int
+}
+
+type hitKey struct {
+	session string
+	node    int
+}
+
+// Search returns the messages matching every term and phrase of the query,
+// best matches first
+func (idx *Index) Search(q *Query) []Hit {
+	// Every word of every phrase must be present as well
+	required := q.HighlightTerms()
+
+	var candidates map[hitKey]map[string][]int
+	for _, term := range required {
+		matches := map[hitKey]map[string][]int{}
+		for _, p := range idx.Postings[term] {
+			key := hitKey{p.Session, p.Node}
+			if candidates != nil {
+				prev, ok := candidates[key]
+				if !ok {
+					continue
+				}
+				matches[key] = prev
+			} else {
+				matches[key] = map[string][]int{}
+			}
+			matches[key][term] = p.Positions
+		}
+		candidates = matches
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	var hits []Hit
+	for key, positions := range candidates {
+		entry := idx.Sessions[key.session]
+		if entry == nil {
+			continue
+		}
+		msg, ok := entry.Messages[key.node]
+		if !ok {
+			continue
+		}
+
+		if q.Role != "" && msg.Role != q.Role {
+			continue
+		}
+		if q.Model != "" && !strings.Contains(strings.ToLower(entry.Model), q.Model) {
+			continue
+		}
+		if !q.After.IsZero() && msg.Timestamp.Before(q.After) {
+			continue
+		}
+		if !q.Before.IsZero() && !msg.Timestamp.Before(q.Before) {
+			continue
+		}
+
+		matched := true
+		for _, phrase := range q.Phrases {
+			if !containsPhrase(positions, phrase) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		score := 0
+		for _, pos := range positions {
+			score += len(pos)
+		}
+		score += 5 * len(q.Phrases)
+
+		hits = append(hits, Hit{
+			Session:   key.session,
+			Node:      key.node,
+			Title:     entry.Title,
+			Model:     entry.Model,
+			Role:      msg.Role,
+			Timestamp: msg.Timestamp,
+			Score:     score,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Timestamp.After(hits[j].Timestamp)
+	})
+
+	return hits
+}
+
+// containsPhrase reports whether the words of phrase occur consecutively
+func containsPhrase(positions map[string][]int, phrase []string) bool {
+	for _, start := range positions[phrase[0]] {
+		found := true
+		for offset, word := range phrase[1:] {
+			if !containsInt(positions[word], start+offset+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is a hit together with the text of the matching message
+type Result struct {
+	Hit
+	Content string
+}
+
+// Find opens the index of store and runs the query, see Index.Find
+func Find(store *session.Store, input string, limit int) (*Query, []Result, error) {
+	idx, err := Open(store)
+	if err != nil {
+		return nil, nil, err
+	}
+	return idx.Find(store, input, limit)
+}
+
+// Find syncs the index with store, runs the query and returns at most limit
+// results with their message text
+func (idx *Index) Find(store *session.Store, input string, limit int) (*Query, []Result, error) {
+	q, err := ParseQuery(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	idx.mu.Lock()
+	if err := idx.sync(store); err != nil {
+		idx.mu.Unlock()
+		return nil, nil, err
+	}
+	hits := idx.Search(q)
+	idx.mu.Unlock()
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	sessions := map[string]*session.Session{}
+	results := make([]Result, 0, len(hits))
+	for _, hit := range hits {
+		s, ok := sessions[hit.Session]
+		if !ok {
+			if s, err = store.Load(hit.Session); err != nil {
+				continue
+			}
+			sessions[hit.Session] = s
+		}
+		node := s.Tree.Node(hit.Node)
+		if node == nil {
+			continue
+		}
+		results = append(results, Result{Hit: hit, Content: node.Message.Content})
+	}
+
+	return q, results, nil
+}
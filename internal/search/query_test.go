@@ -0,0 +1,90 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *Query
+	}{
+		{
+			name:  "words",
+			input: "Mutex  deadlock",
+			want:  &Query{Terms: []string{"mutex", "deadlock"}},
+		},
+		{
+			name:  "phrase",
+			input: `"race condition" go`,
+			want:  &Query{Terms: []string{"go"}, Phrases: [][]string{{"race", "condition"}}},
+		},
+		{
+			name:  "single word phrase is a term",
+			input: `"mutex"`,
+			want:  &Query{Terms: []string{"mutex"}},
+		},
+		{
+			name:  "unterminated phrase",
+			input: `"race condition`,
+			want:  &Query{Phrases: [][]string{{"race", "condition"}}},
+		},
+		{
+			name:  "filters",
+			input: "deploy role:Assistant model:Llama after:2026-01-01 before:2026-02-01",
+			want: &Query{
+				Terms:  []string{"deploy"},
+				Role:   "assistant",
+				Model:  "llama",
+				After:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local),
+				Before: time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:  "unknown filter is searched as words",
+			input: "http://example",
+			want:  &Query{Terms: []string{"http", "example"}},
+		},
+		{
+			name:  "empty filter value is a word",
+			input: "role: deploy",
+			want:  &Query{Terms: []string{"role", "deploy"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"only filters", "role:user model:gpt"},
+		{"only punctuation", `"" !!`},
+		{"bad after date", "deploy after:yesterday"},
+		{"bad before date", "deploy before:2026-13-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if q, err := ParseQuery(tt.input); err == nil {
+				t.Errorf("ParseQuery(%q) = %+v, want an error", tt.input, q)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// snippetContext is the number of runes shown before the first match, at most
+// a third of the snippet width
+const snippetContext = 40
+
+// Snippet returns a single-line excerpt of content around the first match of
+// terms, of at most width runes, with every matching word passed to highlight
+func Snippet(content string, terms []string, width int, highlight func(string) string) string {
+	wanted := map[string]bool{}
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+
+	// Find word boundaries and matches
+	type span struct{ start, end int }
+	var matches []span
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		if wanted[strings.ToLower(string(runes[i:j]))] {
+			matches = append(matches, span{i, j})
+		}
+		i = j
+	}
+
+	context := min(snippetContext, width/3)
+	start := 0
+	if len(matches) > 0 && matches[0].start > context {
+		start = matches[0].start - context
+		// Start on a word boundary
+		for start < matches[0].start && isWordRune(runes[start-1]) {
+			start++
+		}
+	}
+	end := start + width
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var out strings.Builder
+	if start > 0 {
+		out.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		if m.end <= start || m.start >= end {
+			continue
+		}
+		mStart, mEnd := max(m.start, pos), min(m.end, end)
+		out.WriteString(string(runes[pos:mStart]))
+		out.WriteString(highlight(string(runes[mStart:mEnd])))
+		pos = mEnd
+	}
+	out.WriteString(string(runes[pos:end]))
+	if end < len(runes) {
+		out.WriteString("…")
+	}
+
+	return out.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
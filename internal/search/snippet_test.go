@@ -0,0 +1,70 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	mark := func(s string) string { return "[" + s + "]" }
+	long := strings.Repeat("filler ", 10) + "the mutex was held"
+
+	tests := []struct {
+		name    string
+		content string
+		terms   []string
+		width   int
+		want    string
+	}{
+		{
+			name:    "highlights every match",
+			content: "Lock the mutex, then unlock the Mutex",
+			terms:   []string{"mutex"},
+			width:   80,
+			want:    "Lock the [mutex], then unlock the [Mutex]",
+		},
+		{
+			name:    "whole words only",
+			content: "mutexes are not a mutex",
+			terms:   []string{"mutex"},
+			width:   80,
+			want:    "mutexes are not a [mutex]",
+		},
+		{
+			name:    "collapses whitespace",
+			content: "first line\n\n  second   line",
+			terms:   []string{"second"},
+			width:   80,
+			want:    "first line [second] line",
+		},
+		{
+			name:    "truncates at width",
+			content: "deploy the service to production today",
+			terms:   []string{"deploy"},
+			width:   10,
+			want:    "[deploy] the…",
+		},
+		{
+			name:    "starts near a late match on a word boundary",
+			content: long,
+			terms:   []string{"mutex"},
+			width:   30,
+			want:    "…the [mutex] was held",
+		},
+		{
+			name:    "no match shows the start",
+			content: "nothing to see here",
+			terms:   []string{"mutex"},
+			width:   7,
+			want:    "nothing…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.content, tt.terms, tt.width, mark); got != tt.want {
+				t.Errorf("Snippet() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/usage"
+)
+
+// CurrentVersion is the schema version written by Save.
+//
+// Version history:
+//  1. linear list of messages
+//  2. conversation tree with all branches; messages holds the active path
+const CurrentVersion = 2
+
+// Message is a chat message together with its metadata
+type Message struct {
+	Role      string            `json:"role"`
+	Content   string            `json:"content"`
+	Timestamp time.Time         `json:"timestamp"`
+	Stats     *llm.RequestStats `json:"stats,omitempty"`
+	// Pinned messages are always sent, whatever the context policy
+	Pinned bool `json:"pinned,omitempty"`
+	// Summary replaces all earlier messages of the path in the request context
+	Summary string `json:"summary,omitempty"`
+}
+
+// NewMessage creates a message stamped with the current time
+func NewMessage(role, content string) Message {
+	return Message{
+		Role:      role,
+		Content:   content,
+		Timestamp: time.Now(),
+	}
+}
+
+// Session is the on-disk representation of a conversation
+type Session struct {
+	Version      int       `json:"version"`
+	ID           string    `json:"id,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Cwd          string    `json:"cwd,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Model        string    `json:"model"`
+	BaseURL      string    `json:"base_url"`
+	Temperature  float64   `json:"temperature"`
+	MaxTokens    int       `json:"max_tokens"`
+	TurnCount    int       `json:"turns,omitempty"`
+	Preview      string    `json:"preview,omitempty"`
+	SystemPrompt string    `json:"system_prompt"`
+	Messages     []Message `json:"messages"`
+	Tree         *Tree     `json:"tree,omitempty"`
+	// Usage records every request of the session, including those whose
+	// messages are no longer in the tree
+	Usage *usage.Ledger `json:"usage,omitempty"`
+	// Source names the application an imported session came from
+	Source string `json:"source,omitempty"`
+}
+
+// Turns returns the number of user turns in the session
+func (s *Session) Turns() int {
+	turns := 0
+	for _, msg := range s.Messages {
+		if msg.Role == "user" {
+			turns++
+		}
+	}
+	return turns
+}
+
+// DisplayTitle returns the session title, falling back to the first user message
+func (s *Session) DisplayTitle() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	if preview := s.preview(); preview != "" {
+		return preview
+	}
+	return "(empty session)"
+}
+
+// preview returns the start of the first user message
+func (s *Session) preview() string {
+	for _, msg := range s.Messages {
+		if msg.Role == "user" {
+			return Truncate(msg.Content, 60)
+		}
+	}
+	return ""
+}
+
+// Summary returns the listing summary of the session
+func (s *Session) Summary() Summary {
+	return Summary{
+		ID:        s.ID,
+		Title:     s.DisplayTitle(),
+		Model:     s.Model,
+		Cwd:       s.Cwd,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+		Turns:     s.Turns(),
+	}
+}
+
+// Truncate shortens text to a single line of at most max runes
+func Truncate(text string, max int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// Save writes a session to path as indented JSON
+func Save(path string, s *Session) error {
+	s.Version = CurrentVersion
+	if s.Tree == nil {
+		s.Tree = FromMessages(s.Messages)
+	}
+	s.Messages = s.Tree.Messages()
+	if s.Usage == nil || len(s.Usage.Log) == 0 {
+		// Sessions without a ledger, e.g. imported ones, count what the tree holds
+		s.Usage = usage.NewLedger(s.Tree.Stats())
+	}
+	s.TurnCount = s.Turns()
+	s.Preview = s.preview()
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	if s.UpdatedAt.IsZero() {
+		s.UpdatedAt = time.Now()
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a session from path, upgrading older schema versions
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	return Decode(data)
+}
+
+// Decode parses a session from JSON, upgrading older schema versions
+func Decode(data []byte) (*Session, error) {
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	switch {
+	case header.Version == 0:
+		return nil, fmt.Errorf("not a chat-tui session file (missing version)")
+	case header.Version > CurrentVersion:
+		return nil, fmt.Errorf("session version %d is newer than supported version %d", header.Version, CurrentVersion)
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session: %w", err)
+	}
+
+	// Version 1 stored a single branch; upgrade it to a tree
+	if s.Tree == nil || s.Tree.Nodes == nil {
+		s.Tree = FromMessages(s.Messages)
+	} else if err := s.Tree.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid conversation tree: %w", err)
+	}
+	s.Messages = s.Tree.Messages()
+	s.Version = CurrentVersion
+
+	return &s, nil
+}
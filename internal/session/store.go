@@ -0,0 +1,280 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary describes a stored session without its messages
+type Summary struct {
+	ID        string
+	Title     string
+	Model     string
+	Cwd       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Turns     int
+}
+
+// Store keeps sessions as JSON files in a directory
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the session directory under the XDG data directory
+func DefaultDir() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	return filepath.Join(dataHome, "chat-tui", "sessions"), nil
+}
+
+// NewStore opens a session store, creating the directory if needed.
+// An empty dir uses DefaultDir.
+func NewStore(dir string) (*Store, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	return &Store{dir: dir}, nil
+}
+
+// NewID returns a new, time-ordered session ID
+func NewID() string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// StableID returns the session ID of an imported conversation; importing the
+// same source conversation again yields the same ID
+func StableID(created time.Time, source string) string {
+	sum := sha1.Sum([]byte(source))
+	return created.Format("20060102-150405") + "-" + hex.EncodeToString(sum[:3])
+}
+
+// Dir returns the store directory
+func (st *Store) Dir() string {
+	return st.dir
+}
+
+// path returns the file path of a session
+func (st *Store) path(id string) string {
+	return filepath.Join(st.dir, id+".json")
+}
+
+// Save writes a session to the store, assigning an ID if it has none
+func (st *Store) Save(s *Session) error {
+	if s.ID == "" {
+		s.ID = NewID()
+	}
+
+	// Write to a temp file first so a crash never leaves a truncated session
+	tmp := st.path(s.ID) + ".tmp"
+	if err := Save(tmp, s); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, st.path(s.ID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a session by ID or unique ID prefix
+func (st *Store) Load(id string) (*Session, error) {
+	if _, err := os.Stat(st.path(id)); err == nil {
+		return Load(st.path(id))
+	}
+
+	summaries, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, sum := range summaries {
+		if strings.HasPrefix(sum.ID, id) {
+			matches = append(matches, sum.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("session not found: %s", id)
+	case 1:
+		return Load(st.path(matches[0]))
+	default:
+		return nil, fmt.Errorf("session ID %s is ambiguous (%d matches)", id, len(matches))
+	}
+}
+
+// List returns summaries of all stored sessions, most recently updated first
+func (st *Store) List() ([]Summary, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	var summaries []Summary
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		sum, err := readSummary(filepath.Join(st.dir, entry.Name()))
+		if err != nil {
+			// Skip unreadable or foreign files
+			continue
+		}
+		if sum.ID == "" {
+			sum.ID = strings.TrimSuffix(entry.Name(), ".json")
+		}
+		summaries = append(summaries, sum)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].UpdatedAt.After(summaries[j].UpdatedAt)
+	})
+
+	return summaries, nil
+}
+
+// readSummary reads the summary of a session file from the fields written
+// before its messages, so listing does not parse whole conversations. Files
+// saved before sessions recorded their turn count are loaded in full.
+func readSummary(path string) (Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to read session: %w", err)
+	}
+	defer f.Close()
+
+	var header Session
+	fields := map[string]any{
+		"version":    &header.Version,
+		"id":         &header.ID,
+		"title":      &header.Title,
+		"cwd":        &header.Cwd,
+		"created_at": &header.CreatedAt,
+		"updated_at": &header.UpdatedAt,
+		"model":      &header.Model,
+		"turns":      &header.TurnCount,
+		"preview":    &header.Preview,
+	}
+
+	dec := json.NewDecoder(f)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return Summary{}, fmt.Errorf("failed to parse session: not a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return Summary{}, fmt.Errorf("failed to parse session: %w", err)
+		}
+		name, _ := tok.(string)
+		if name == "messages" {
+			break
+		}
+		var value any = new(json.RawMessage)
+		if field, ok := fields[name]; ok {
+			value = field
+		}
+		if err := dec.Decode(value); err != nil {
+			return Summary{}, fmt.Errorf("failed to parse session: %w", err)
+		}
+	}
+
+	switch {
+	case header.Version == 0 || header.Version > CurrentVersion:
+		return Summary{}, fmt.Errorf("not a supported chat-tui session file")
+	case header.TurnCount == 0:
+		s, err := Load(path)
+		if err != nil {
+			return Summary{}, err
+		}
+		return s.Summary(), nil
+	}
+
+	title := header.Title
+	if title == "" {
+		title = header.Preview
+	}
+	return Summary{
+		ID:        header.ID,
+		Title:     title,
+		Model:     header.Model,
+		Cwd:       header.Cwd,
+		CreatedAt: header.CreatedAt,
+		UpdatedAt: header.UpdatedAt,
+		Turns:     header.TurnCount,
+	}, nil
+}
+
+// ModTime returns the modification time of a stored session file
+func (st *Store) ModTime(id string) (time.Time, error) {
+	info, err := os.Stat(st.path(id))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read session: %w", err)
+	}
+	return info.ModTime(), nil
+}
+
+// ModTimes returns the modification time of every stored session file by ID
+func (st *Store) ModTimes() (map[string]time.Time, error) {
+	entries, err := os.ReadDir(st.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session directory: %w", err)
+	}
+
+	times := make(map[string]time.Time, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		times[strings.TrimSuffix(entry.Name(), ".json")] = info.ModTime()
+	}
+
+	return times, nil
+}
+
+// Latest returns the most recently updated session started in cwd
+func (st *Store) Latest(cwd string) (*Summary, error) {
+	summaries, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sum := range summaries {
+		if sum.Cwd == cwd {
+			return &sum, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no saved session for %s", cwd)
+}
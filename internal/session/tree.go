@@ -0,0 +1,388 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+)
+
+// noParent is the parent ID of top-level nodes
+const noParent = -1
+
+// Node is one message in a conversation tree
+type Node struct {
+	ID       int     `json:"id"`
+	Parent   int     `json:"parent"`
+	Message  Message `json:"message"`
+	Children []int   `json:"children,omitempty"`
+	// Active is the index into Children of the branch being followed,
+	// or -1 when the active path ends at this node
+	Active int `json:"active"`
+}
+
+// Tree stores a conversation with all of its branches. The active path runs
+// from the active root through the active child of every node.
+type Tree struct {
+	Nodes  map[int]*Node `json:"nodes"`
+	Roots  []int         `json:"roots"`
+	Active int           `json:"active"`
+	NextID int           `json:"next_id"`
+}
+
+// NewTree creates an empty tree
+func NewTree() *Tree {
+	return &Tree{
+		Nodes:  map[int]*Node{},
+		Active: -1,
+	}
+}
+
+// FromMessages creates a single-branch tree from a linear conversation
+func FromMessages(messages []Message) *Tree {
+	t := NewTree()
+	for _, msg := range messages {
+		t.Append(msg)
+	}
+	return t
+}
+
+// Node returns the node with the given ID, or nil
+func (t *Tree) Node(id int) *Node {
+	return t.Nodes[id]
+}
+
+// Len returns the number of nodes in the tree
+func (t *Tree) Len() int {
+	return len(t.Nodes)
+}
+
+// Children returns the child IDs of parent; noParent (-1) returns the roots
+func (t *Tree) Children(parent int) []int {
+	if parent == noParent {
+		return t.Roots
+	}
+	if node := t.Nodes[parent]; node != nil {
+		return node.Children
+	}
+	return nil
+}
+
+// activeIndex returns the active child index of parent
+func (t *Tree) activeIndex(parent int) int {
+	if parent == noParent {
+		return t.Active
+	}
+	if node := t.Nodes[parent]; node != nil {
+		return node.Active
+	}
+	return -1
+}
+
+// setActiveIndex sets the active child index of parent
+func (t *Tree) setActiveIndex(parent, index int) {
+	if parent == noParent {
+		t.Active = index
+		return
+	}
+	t.Nodes[parent].Active = index
+}
+
+// Path returns the node IDs of the active path, root first
+func (t *Tree) Path() []int {
+	var path []int
+	parent := noParent
+	for {
+		children := t.Children(parent)
+		index := t.activeIndex(parent)
+		if index < 0 || index >= len(children) {
+			return path
+		}
+		parent = children[index]
+		path = append(path, parent)
+	}
+}
+
+// Messages returns the messages of the active path
+func (t *Tree) Messages() []Message {
+	path := t.Path()
+	messages := make([]Message, len(path))
+	for i, id := range path {
+		messages[i] = t.Nodes[id].Message
+	}
+	return messages
+}
+
+// Validate checks a tree read from disk: every root and child exists and
+// links back to its parent, active indices are in range, every node is
+// reachable from the roots exactly once and NextID is unused
+func (t *Tree) Validate() error {
+	if t.Active < -1 || t.Active >= len(t.Roots) {
+		return fmt.Errorf("active root %d out of range", t.Active)
+	}
+
+	seen := map[int]bool{}
+	var visit func(parent int, children []int) error
+	visit = func(parent int, children []int) error {
+		for _, id := range children {
+			node := t.Nodes[id]
+			switch {
+			case node == nil:
+				return fmt.Errorf("node %d is missing", id)
+			case node.ID != id:
+				return fmt.Errorf("node %d is stored as %d", node.ID, id)
+			case node.Parent != parent:
+				return fmt.Errorf("node %d has parent %d instead of %d", id, node.Parent, parent)
+			case seen[id]:
+				return fmt.Errorf("node %d appears twice", id)
+			case node.Active < -1 || node.Active >= len(node.Children):
+				return fmt.Errorf("active child %d of node %d out of range", node.Active, id)
+			case id >= t.NextID:
+				return fmt.Errorf("node %d is not below next ID %d", id, t.NextID)
+			}
+			seen[id] = true
+			if err := visit(id, node.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(noParent, t.Roots); err != nil {
+		return err
+	}
+
+	if len(seen) != len(t.Nodes) {
+		return fmt.Errorf("%d nodes are not reachable from the roots", len(t.Nodes)-len(seen))
+	}
+	return nil
+}
+
+// Leaf returns the last node of the active path, or -1 for an empty path
+func (t *Tree) Leaf() int {
+	path := t.Path()
+	if len(path) == 0 {
+		return noParent
+	}
+	return path[len(path)-1]
+}
+
+// Append adds msg after the last node of the active path and returns its ID
+func (t *Tree) Append(msg Message) int {
+	return t.AddChild(t.Leaf(), msg)
+}
+
+// AddChild adds msg as the last child of parent, makes it active and returns its ID
+func (t *Tree) AddChild(parent int, msg Message) int {
+	id := t.NextID
+	t.NextID++
+
+	t.Nodes[id] = &Node{
+		ID:      id,
+		Parent:  parent,
+		Message: msg,
+		Active:  -1,
+	}
+
+	if parent == noParent {
+		t.Roots = append(t.Roots, id)
+		t.Active = len(t.Roots) - 1
+	} else {
+		node := t.Nodes[parent]
+		node.Children = append(node.Children, id)
+		node.Active = len(node.Children) - 1
+	}
+
+	return id
+}
+
+// AddSibling adds msg as a new branch next to node id, makes it active and returns its ID
+func (t *Tree) AddSibling(id int, msg Message) int {
+	return t.AddChild(t.Nodes[id].Parent, msg)
+}
+
+// InsertRoot inserts msg above all current roots and returns its ID
+func (t *Tree) InsertRoot(msg Message) int {
+	roots, active := t.Roots, t.Active
+	t.Roots, t.Active = nil, -1
+
+	id := t.AddChild(noParent, msg)
+	node := t.Nodes[id]
+	node.Children = roots
+	node.Active = active
+	for _, child := range roots {
+		t.Nodes[child].Parent = id
+	}
+
+	return id
+}
+
+// Siblings returns the position of node id among its siblings (0-based) and the sibling count
+func (t *Tree) Siblings(id int) (int, int) {
+	node := t.Nodes[id]
+	if node == nil {
+		return 0, 0
+	}
+	siblings := t.Children(node.Parent)
+	for i, sibling := range siblings {
+		if sibling == id {
+			return i, len(siblings)
+		}
+	}
+	return 0, len(siblings)
+}
+
+// SwitchSibling activates the sibling delta positions away from node id
+// (wrapping around) and returns its ID
+func (t *Tree) SwitchSibling(id, delta int) int {
+	node := t.Nodes[id]
+	if node == nil {
+		return id
+	}
+	index, count := t.Siblings(id)
+	if count < 2 {
+		return id
+	}
+	index = ((index+delta)%count + count) % count
+	t.setActiveIndex(node.Parent, index)
+	return t.Children(node.Parent)[index]
+}
+
+// Activate makes node id part of the active path; the path continues below
+// it along the active children
+func (t *Tree) Activate(id int) {
+	for node := t.Nodes[id]; node != nil; node = t.Nodes[node.Parent] {
+		index, _ := t.Siblings(node.ID)
+		t.setActiveIndex(node.Parent, index)
+	}
+}
+
+// EndPathAt activates node id and ends the active path there, so the next
+// appended message starts a new branch below it
+func (t *Tree) EndPathAt(id int) {
+	t.Activate(id)
+	t.Nodes[id].Active = -1
+}
+
+// Remove deletes node id and its subtree; the active path then ends at its parent
+func (t *Tree) Remove(id int) {
+	node := t.Nodes[id]
+	if node == nil {
+		return
+	}
+
+	siblings := t.Children(node.Parent)
+	remaining := make([]int, 0, len(siblings))
+	for _, sibling := range siblings {
+		if sibling != id {
+			remaining = append(remaining, sibling)
+		}
+	}
+	if node.Parent == noParent {
+		t.Roots = remaining
+	} else {
+		t.Nodes[node.Parent].Children = remaining
+	}
+	t.setActiveIndex(node.Parent, -1)
+
+	var drop func(int)
+	drop = func(id int) {
+		for _, child := range t.Nodes[id].Children {
+			drop(child)
+		}
+		delete(t.Nodes, id)
+	}
+	drop(id)
+}
+
+// Splice deletes node id alone: its children take its place among its
+// siblings, so the rest of the conversation is kept
+func (t *Tree) Splice(id int) {
+	node := t.Nodes[id]
+	if node == nil {
+		return
+	}
+
+	siblings := t.Children(node.Parent)
+	pos, _ := t.Siblings(id)
+	spliced := make([]int, 0, len(siblings)+len(node.Children)-1)
+	spliced = append(spliced, siblings[:pos]...)
+	spliced = append(spliced, node.Children...)
+	spliced = append(spliced, siblings[pos+1:]...)
+	for _, child := range node.Children {
+		t.Nodes[child].Parent = node.Parent
+	}
+
+	active := t.activeIndex(node.Parent)
+	switch {
+	case active == pos && node.Active >= 0:
+		active = pos + node.Active
+	case active == pos:
+		// The path ended at the deleted node
+		active = -1
+	case active > pos:
+		active += len(node.Children) - 1
+	}
+
+	if node.Parent == noParent {
+		t.Roots = spliced
+	} else {
+		t.Nodes[node.Parent].Children = spliced
+	}
+	t.setActiveIndex(node.Parent, active)
+	delete(t.Nodes, id)
+}
+
+// Clone returns a deep copy of the tree
+func (t *Tree) Clone() *Tree {
+	c := &Tree{
+		Nodes:  make(map[int]*Node, len(t.Nodes)),
+		Roots:  append([]int(nil), t.Roots...),
+		Active: t.Active,
+		NextID: t.NextID,
+	}
+	for id, node := range t.Nodes {
+		n := *node
+		n.Children = append([]int(nil), node.Children...)
+		c.Nodes[id] = &n
+	}
+	return c
+}
+
+// Walk visits every node depth-first in branch order, with its branch depth
+// (how many forks lie above it) and whether it lies on the active path
+func (t *Tree) Walk(fn func(node *Node, depth int, active bool)) {
+	onPath := map[int]bool{}
+	for _, id := range t.Path() {
+		onPath[id] = true
+	}
+
+	var visit func(id, depth int)
+	visit = func(id, depth int) {
+		node := t.Nodes[id]
+		fn(node, depth, onPath[id])
+		childDepth := depth
+		if len(node.Children) > 1 {
+			childDepth++
+		}
+		for _, child := range node.Children {
+			visit(child, childDepth)
+		}
+	}
+	rootDepth := 0
+	if len(t.Roots) > 1 {
+		rootDepth = 1
+	}
+	for _, root := range t.Roots {
+		visit(root, rootDepth)
+	}
+}
+
+// Stats returns the request stats of every message in the tree, on all branches
+func (t *Tree) Stats() []*llm.RequestStats {
+	var stats []*llm.RequestStats
+	for _, node := range t.Nodes {
+		if node.Message.Stats != nil {
+			stats = append(stats, node.Message.Stats)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,219 @@
+package theme
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"gopkg.in/yaml.v3"
+)
+
+// Built-in theme names
+const (
+	Dark         = "dark"
+	Light        = "light"
+	HighContrast = "high-contrast"
+)
+
+//go:embed styles/high-contrast.json
+var highContrastGlamour string
+
+// Palette holds the interface colors as ANSI 256 numbers or hex values
+type Palette struct {
+	Primary        string `yaml:"primary"`         // user messages, borders, stat values
+	Secondary      string `yaml:"secondary"`       // assistant messages, banner subtitle
+	Accent         string `yaml:"accent"`          // titles, commands, selection
+	Muted          string `yaml:"muted"`           // system messages, help, labels
+	Text           string `yaml:"text"`            // list items and code
+	Error          string `yaml:"error"`           // errors, a full context gauge
+	Success        string `yaml:"success"`         // notices, the selected suggestion
+	CodeBackground string `yaml:"code_background"` // code blocks
+}
+
+// Theme is a palette with a matching markdown style
+type Theme struct {
+	Name    string  `yaml:"name"`
+	Palette Palette `yaml:"palette"`
+	// Glamour is a glamour style name (dark, light, dracula, ...), a path to a
+	// glamour style JSON file, or the style JSON itself
+	Glamour string `yaml:"glamour"`
+	// Chroma is the chroma style highlighting code blocks (monokai, github, ...)
+	Chroma string `yaml:"chroma"`
+}
+
+// builtins are the themes that need no file
+var builtins = map[string]Theme{
+	Dark: {
+		Name: Dark,
+		Palette: Palette{
+			Primary:        "86",
+			Secondary:      "212",
+			Accent:         "220",
+			Muted:          "240",
+			Text:           "252",
+			Error:          "196",
+			Success:        "46",
+			CodeBackground: "235",
+		},
+		Glamour: "dark",
+		Chroma:  "monokai",
+	},
+	Light: {
+		Name: Light,
+		Palette: Palette{
+			Primary:        "30",
+			Secondary:      "127",
+			Accent:         "130",
+			Muted:          "244",
+			Text:           "235",
+			Error:          "160",
+			Success:        "28",
+			CodeBackground: "254",
+		},
+		Glamour: "light",
+		Chroma:  "github",
+	},
+	HighContrast: {
+		Name: HighContrast,
+		Palette: Palette{
+			Primary:        "14",
+			Secondary:      "13",
+			Accent:         "11",
+			Muted:          "250",
+			Text:           "15",
+			Error:          "9",
+			Success:        "10",
+			CodeBackground: "0",
+		},
+		Glamour: highContrastGlamour,
+		Chroma:  "hr_high_contrast",
+	},
+}
+
+// Default returns the default dark theme
+func Default() *Theme {
+	t := builtins[Dark]
+	return &t
+}
+
+// DefaultDir returns the user theme directory under the XDG config directory
+func DefaultDir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "chat-tui", "themes"), nil
+}
+
+// Load returns the built-in theme called name, or the user theme
+// <dir>/<name>.yaml, ignoring case. An empty name is the dark theme; an empty
+// dir uses DefaultDir.
+func Load(name, dir string) (*Theme, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = Dark
+	}
+	if t, ok := builtins[strings.ToLower(name)]; ok {
+		return &t, nil
+	}
+
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	names := Names(dir)
+	found := false
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			// Open the file under its real name
+			name, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	path := filepath.Join(dir, name+".yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+	return parse(name, path, data)
+}
+
+// parse reads a user theme. Colors it leaves out come from its base theme
+// ("base: light"), dark by default.
+func parse(name, path string, data []byte) (*Theme, error) {
+	var header struct {
+		Base string `yaml:"base"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to parse theme %s: %w", path, err)
+	}
+	if header.Base == "" {
+		header.Base = Dark
+	}
+	base, ok := builtins[header.Base]
+	if !ok {
+		return nil, fmt.Errorf("theme %s: unknown base theme %q", path, header.Base)
+	}
+
+	t := base
+	t.Name = name
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse theme %s: %w", path, err)
+	}
+
+	// Style files are relative to the theme
+	if isStylePath(t.Glamour) && !filepath.IsAbs(t.Glamour) {
+		t.Glamour = filepath.Join(filepath.Dir(path), t.Glamour)
+	}
+	return &t, nil
+}
+
+// Names lists the built-in themes followed by the user themes in dir
+func Names(dir string) []string {
+	names := []string{Dark, Light, HighContrast}
+	if dir == "" {
+		dir, _ = DefaultDir()
+	}
+	entries, _ := os.ReadDir(dir)
+	var user []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if _, builtin := builtins[strings.ToLower(name)]; ok && !builtin && !entry.IsDir() {
+			user = append(user, name)
+		}
+	}
+	sort.Strings(user)
+	return append(names, user...)
+}
+
+// GlamourOption returns the glamour option rendering markdown in this theme
+func (t *Theme) GlamourOption() glamour.TermRendererOption {
+	style := strings.TrimSpace(t.Glamour)
+	switch {
+	case style == "":
+		return glamour.WithStandardStyle(Dark)
+	case strings.HasPrefix(style, "{"):
+		return glamour.WithStylesFromJSONBytes([]byte(style))
+	default:
+		// Standard style name or style file
+		return glamour.WithStylePath(style)
+	}
+}
+
+// isStylePath reports whether a glamour setting names a style file
+func isStylePath(style string) bool {
+	return strings.HasSuffix(strings.TrimSpace(style), ".json")
+}
@@ -2,25 +2,50 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/LETHEVIET/chat-tui/internal/commands"
 	"github.com/LETHEVIET/chat-tui/internal/config"
+	"github.com/LETHEVIET/chat-tui/internal/contextwin"
+	"github.com/LETHEVIET/chat-tui/internal/debug"
+	"github.com/LETHEVIET/chat-tui/internal/keymap"
 	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/search"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/theme"
 	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	"github.com/LETHEVIET/chat-tui/internal/usage"
 	"github.com/LETHEVIET/chat-tui/internal/version"
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// defaultWidth is the terminal width assumed until the first resize
+const defaultWidth = 100
+
 // ChatModel is the main Bubble Tea model for the chat interface
 type ChatModel struct {
 	config             *config.Config
 	client             llm.Client
-	messages           []llm.Message
+	logger             *debug.Logger
+	tree               *session.Tree
+	messages           []session.Message
+	path               []int
+	branchCursor       int
+	streamFallback     int
+	retryBatch         *retryBatch
+	pendingSummary     *summaryRequest
+	cancelStream       context.CancelFunc
 	input              *components.InputComponent
 	messageComp        *components.MessageComponent
+	theme              *theme.Theme
 	stats              *components.StatsComponent
 	streaming          bool
 	streamContent      string
@@ -34,15 +59,51 @@ type ChatModel struct {
 	suggestions        []commands.CommandDef
 	selectedSuggestion int
 	showBanner         bool
+	compare            *compareState
+	createdAt          time.Time
+	store              *session.Store
+	ledger             *usage.Ledger    // every request of the session
+	searchIndex        *search.Index    // opened on first use
+	indexQueue         *session.Session // last saved session waiting to be indexed
+	indexing           bool             // an index update is running
+	sessionID          string
+	source             string // application an imported session came from
+	cwd                string
+	picker             *components.PickerComponent
+	onPick             func(components.PickerItem) tea.Cmd
+	focusMessage       int
+	scrollToFocus      bool
+	selection          *selectionState
+	title              string
+	titleRequested     bool
+	shownTitle         string
+	undoStack          []snapshot
+	redoStack          []snapshot
+	confirm            *confirmState
+	notice             string
+	fullscreen         bool
+	viewport           viewport.Model
+	viewportContent    string // last transcript handed to the viewport
+	history            historyCache
+	follow             bool
+	codeFocus          int
+	scrollback         scrollbackState
+	live               *liveStats // measurements of the streaming reply
+	keys               *keymap.KeyMap
+	showKeys           bool   // key bindings overlay
+	report             string // command output overlay, in markdown
+	window             *contextwin.Window
 }
 
 // Messages for async operations
 type streamChunkMsg struct {
-	chunk llm.StreamChunk
+	source <-chan llm.StreamChunk
+	chunk  llm.StreamChunk
 }
 
 type streamCompleteMsg struct {
-	stats *llm.RequestStats
+	source <-chan llm.StreamChunk
+	stats  *llm.RequestStats
 }
 
 type errorMsg struct {
@@ -55,20 +116,36 @@ type configReloadedMsg struct {
 
 // NewChatModel creates a new chat model
 func NewChatModel(cfg *config.Config) (*ChatModel, error) {
+	if !contextwin.ValidPolicy(cfg.Context.Policy) {
+		return nil, fmt.Errorf("invalid context policy %q (use none, sliding or summarize)", cfg.Context.Policy)
+	}
+
+	// Create debug logger
+	logger, err := debug.NewLogger(cfg.Debug.LogFile, cfg.Debug.Verbose)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug logger: %w", err)
+	}
+
 	// Create LLM client
-	client := llm.NewOpenAIClient(
-		cfg.APIKey,
-		cfg.BaseURL,
-		cfg.Model,
-		cfg.Temperature,
-		cfg.MaxTokens,
-	)
+	client := newClient(cfg, logger)
+
+	// Invalid bindings are shown once the chat starts; the rest still apply
+	keys, keysErr := keymap.New(cfg.Keys)
 
 	// Create UI components
 	input := components.NewInputComponent()
+	input.SetHistoryKeys(keys.Prev, keys.Next)
+
+	th, err := theme.Load(cfg.UI.Theme, "")
+	if err != nil {
+		logger.Close()
+		return nil, err
+	}
+	ApplyTheme(th)
 
-	messageComp, err := components.NewMessageComponent(100)
+	messageComp, err := components.NewMessageComponent(defaultWidth, th, codeOptions(cfg))
 	if err != nil {
+		logger.Close()
 		return nil, fmt.Errorf("failed to create message component: %w", err)
 	}
 
@@ -78,34 +155,118 @@ func NewChatModel(cfg *config.Config) (*ChatModel, error) {
 	}
 
 	// Initialize with system prompt
-	messages := []llm.Message{}
+	tree := session.NewTree()
 	if cfg.SystemPrompt != "" {
-		messages = append(messages, llm.Message{
-			Role:    "system",
-			Content: cfg.SystemPrompt,
-		})
-	}
-
-	return &ChatModel{
-		config:       cfg,
-		client:       client,
-		messages:     messages,
-		input:        input,
-		messageComp:  messageComp,
-		stats:        stats,
-		systemPrompt: cfg.SystemPrompt,
-		ready:        true,
-		showBanner:   true,
-	}, nil
+		tree.Append(session.NewMessage("system", cfg.SystemPrompt))
+	}
+
+	// Open the session store; persistence is best-effort, but the user is told
+	// when sessions will not be saved
+	var startErrs []error
+	store, err := session.NewStore(cfg.Sessions.Dir)
+	if err != nil {
+		logger.Slog().Warn("session store unavailable", "error", err.Error())
+		startErrs = append(startErrs, fmt.Errorf("session store unavailable, sessions will not be saved: %w", err))
+		store = nil
+	}
+	cwd, _ := os.Getwd()
+
+	m := &ChatModel{
+		config:         cfg,
+		client:         client,
+		logger:         logger,
+		tree:           tree,
+		input:          input,
+		messageComp:    messageComp,
+		theme:          th,
+		stats:          stats,
+		systemPrompt:   cfg.SystemPrompt,
+		ready:          true,
+		showBanner:     true,
+		createdAt:      time.Now(),
+		store:          store,
+		cwd:            cwd,
+		focusMessage:   -1,
+		branchCursor:   -1,
+		streamFallback: -1,
+		fullscreen:     cfg.UI.Fullscreen,
+		viewport:       viewport.New(0, 0),
+		follow:         true,
+		keys:           keys,
+		ledger:         &usage.Ledger{},
+	}
+	if keysErr != nil {
+		startErrs = append(startErrs, fmt.Errorf("invalid key bindings, ignoring them:\n%w", keysErr))
+	}
+	m.err = errors.Join(startErrs...)
+	m.syncMessages()
+
+	return m, nil
+}
+
+// newClient creates an LLM client from the configuration
+func newClient(cfg *config.Config, logger *debug.Logger) llm.Client {
+	client := llm.NewOpenAIClient(
+		cfg.APIKey,
+		cfg.BaseURL,
+		cfg.Model,
+		cfg.Temperature,
+		cfg.MaxTokens,
+	)
+	client.SetLogger(logger.Slog())
+	return client
+}
+
+// codeOptions returns how code blocks are drawn according to the configuration
+func codeOptions(cfg *config.Config) components.CodeOptions {
+	return components.CodeOptions{
+		Highlight:   cfg.UI.SyntaxHighlight,
+		LineNumbers: cfg.UI.LineNumbers,
+		Wrap:        cfg.UI.CodeWrap,
+	}
+}
+
+// contentWidth returns the width messages are wrapped to: the terminal width,
+// capped by ui.max_width
+func (m *ChatModel) contentWidth() int {
+	width := m.width
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if m.sideStatsPane() {
+		width -= components.StatsPanelWidth
+	}
+	if m.config.UI.MaxWidth > 0 {
+		width = min(width, m.config.UI.MaxWidth)
+	}
+	return width
+}
+
+// resizeMessages rewraps messages after the terminal or ui.max_width changed
+func (m *ChatModel) resizeMessages() {
+	if err := m.messageComp.SetWidth(m.contentWidth()); err != nil {
+		m.err = err
+	}
+}
+
+// Close releases resources held by the model
+func (m *ChatModel) Close() error {
+	return m.logger.Close()
 }
 
 // Init initializes the model
 func (m *ChatModel) Init() tea.Cmd {
-	return m.input.Init()
+	return tea.Batch(m.input.Init(), m.syncWindowTitle(), m.flushScrollback())
 }
 
 // Update handles messages
 func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	return model, tea.Batch(cmd, m.syncWindowTitle(), m.flushScrollback(), m.indexSaved())
+}
+
+// update handles messages for Update
+func (m *ChatModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -113,36 +274,161 @@ func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.input.SetWidth(msg.Width - 4)
+		m.resizeMessages()
 
 	case streamStartMsg:
+		if !m.streaming {
+			// Cancelled before the response started
+			return m, nil
+		}
 		m.streamChan = msg.chunks
 		m.streamStats = msg.stats
 		return m, m.waitForChunk()
 
+	case compareStartMsg, compareChunkMsg:
+		return m, m.updateCompare(msg)
+
+	case retryResultMsg:
+		m.updateRetry(msg)
+		return m, nil
+
+	case editorFinishedMsg:
+		return m, m.applyEdit(msg)
+
+	case codeOpenedMsg:
+		m.err = msg.err
+		return m, nil
+
+	case summaryMsg:
+		return m, m.applySummary(msg)
+
+	case titleMsg:
+		m.applyTitle(msg)
+		return m, nil
+
+	case scrollbackMsg:
+		m.scrollback.pending = false
+		return m, nil
+
+	case tea.MouseMsg:
+		m.updateMouse(msg)
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.updateScroll(msg) {
+			return m, nil
+		}
+
+		if m.showKeys || m.report != "" {
+			// Any key closes the key bindings and report overlays
+			m.showKeys = false
+			m.report = ""
+			return m, nil
+		}
+
 		if m.streaming {
 			// Allow Ctrl+C to cancel streaming
-			if msg.Type == tea.KeyCtrlC {
+			if key.Matches(msg, m.keys.Cancel) {
 				m.streaming = false
 				m.err = fmt.Errorf("streaming cancelled")
+				if m.cancelStream != nil {
+					m.cancelStream()
+					m.cancelStream = nil
+				}
+				m.streamChan = nil
+				m.live = nil
+				m.streamContent = ""
+				if m.compare != nil {
+					m.cancelCompare()
+				}
+				m.cancelRetry()
+				m.pendingSummary = nil
+				m.restoreStreamFallback()
 				return m, nil
 			}
 			return m, nil
 		}
 
-		switch msg.Type {
-		case tea.KeyCtrlC:
+		m.notice = ""
+		if m.confirm != nil {
+			return m, m.updateConfirm(msg)
+		}
+
+		if m.picker != nil {
+			return m, m.updatePicker(msg)
+		}
+
+		if m.selection != nil {
+			return m, m.updateSelection(msg)
+		}
+
+		// Pick a compare winner by the key of its column
+		if m.compare != nil && m.compare.awaitingPick && m.input.Value() == "" &&
+			key.Matches(msg, m.keys.ComparePick) {
+			if err := m.pickCompareWinner(slices.Index(m.keys.ComparePick.Keys(), msg.String()) + 1); err != nil {
+				m.err = err
+			} else {
+				m.err = nil
+			}
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Select):
+			// Select messages to act on
+			if m.input.Value() == "" && m.startSelection() {
+				return m, nil
+			}
+
+		case key.Matches(msg, m.keys.Undo):
+			m.runUndo("Undid", m.undo)
+			return m, nil
+		case key.Matches(msg, m.keys.Redo):
+			m.runUndo("Redid", m.redo)
+			return m, nil
+
+		// Navigate between sibling branches
+		case key.Matches(msg, m.keys.BranchPrev):
+			m.switchBranch(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.BranchNext):
+			m.switchBranch(1)
+			return m, nil
+		case key.Matches(msg, m.keys.BranchUp):
+			m.moveBranchCursor(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.BranchDown):
+			m.moveBranchCursor(1)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 
-		case tea.KeyCtrlS:
-			m.stats.Toggle()
+		case key.Matches(msg, m.keys.Help):
+			m.showKeys = true
+			return m, nil
+
+		case key.Matches(msg, m.keys.Stats):
+			m.toggleStats(false)
+			return m, nil
+
+		case key.Matches(msg, m.keys.StatsPanel):
+			m.toggleStats(true)
 			return m, nil
 
-		case tea.KeyCtrlD:
+		case key.Matches(msg, m.keys.Multiline):
 			m.input.ToggleMultilineMode()
 			return m, nil
 
-		case tea.KeyTab:
+		case key.Matches(msg, m.keys.CodeFocus):
+			if err := m.cycleCodeFocus(); err != nil {
+				m.err = err
+			} else {
+				m.err = nil
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Complete):
 			// Autocomplete command
 			if len(m.suggestions) > 0 {
 				suggestion := m.suggestions[m.selectedSuggestion]
@@ -152,7 +438,7 @@ func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case tea.KeyUp:
+		case key.Matches(msg, m.keys.Prev):
 			// Navigate suggestions up (only if we have suggestions)
 			if len(m.suggestions) > 1 {
 				m.selectedSuggestion--
@@ -162,7 +448,7 @@ func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-		case tea.KeyDown:
+		case key.Matches(msg, m.keys.Next):
 			// Navigate suggestions down (only if we have suggestions)
 			if len(m.suggestions) > 1 {
 				m.selectedSuggestion++
@@ -172,7 +458,7 @@ func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-		case tea.KeyEnter:
+		case key.Matches(msg, m.keys.Send):
 			input := strings.TrimSpace(m.input.Value())
 			if input == "" {
 				return m, nil
@@ -194,11 +480,17 @@ func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.handleCommand(input)
 			}
 
+			if m.compare != nil && m.compare.awaitingPick {
+				m.err = fmt.Errorf("pick an answer first (%s)", m.comparePickHint())
+				return m, nil
+			}
+
+			m.focusMessage = -1
+			m.branchCursor = -1
+			m.follow = true
+
 			// Add user message
-			m.messages = append(m.messages, llm.Message{
-				Role:    "user",
-				Content: input,
-			})
+			m.appendMessage(session.NewMessage("user", input))
 
 			// Add input to history before resetting
 			m.input.AddToHistory(input)
@@ -206,62 +498,114 @@ func (m *ChatModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.input.Reset()
 			m.streaming = true
 			m.streamContent = ""
+			m.err = nil
 
+			if m.compare != nil {
+				return m, m.streamCompare()
+			}
+			if m.needsSummary() {
+				if cmd, err := m.summarize(true); err == nil {
+					return m, cmd
+				}
+			}
 			return m, m.streamResponse()
 		}
 
 	case streamChunkMsg:
+		if msg.source != m.streamChan {
+			// Chunk of a cancelled stream
+			return m, nil
+		}
 		if msg.chunk.Error != nil {
 			m.streaming = false
 			m.streamChan = nil
+			m.live = nil
 			m.err = msg.chunk.Error
+			m.restoreStreamFallback()
 			return m, nil
 		}
 
 		if msg.chunk.Done {
 			m.streaming = false
 			m.streamChan = nil
+			m.live = nil
+			m.recordStats(m.streamStats)
 			// Add assistant message
 			if m.streamContent != "" {
-				m.messages = append(m.messages, llm.Message{
-					Role:    "assistant",
-					Content: m.streamContent,
-				})
+				reply := session.NewMessage("assistant", m.streamContent)
+				reply.Stats = m.streamStats
+				m.appendMessage(reply)
+				m.streamFallback = -1
+			} else {
+				m.restoreStreamFallback()
 			}
-			m.stats.SetStats(m.streamStats)
-			return m, nil
+			m.autosave()
+			return m, m.generateTitle()
 		}
 
 		m.streamContent += msg.chunk.Content
+		if msg.chunk.Content != "" && m.live != nil {
+			m.live.observe(time.Now())
+		}
 		return m, m.waitForChunk()
 
+	case liveTickMsg:
+		return m, m.updateLiveTick(msg)
+
 	case streamCompleteMsg:
+		if msg.source != m.streamChan {
+			return m, nil
+		}
 		m.streaming = false
 		m.streamChan = nil
-		m.stats.SetStats(msg.stats)
+		m.live = nil
+		m.recordStats(msg.stats)
 		if m.streamContent != "" {
-			m.messages = append(m.messages, llm.Message{
-				Role:    "assistant",
-				Content: m.streamContent,
-			})
+			reply := session.NewMessage("assistant", m.streamContent)
+			reply.Stats = msg.stats
+			m.appendMessage(reply)
+			m.streamFallback = -1
+		} else {
+			m.restoreStreamFallback()
 		}
-		return m, nil
+		m.autosave()
+		return m, m.generateTitle()
 
 	case errorMsg:
 		m.err = msg.err
 		m.streaming = false
+		m.live = nil
+		m.restoreStreamFallback()
+		return m, nil
+
+	case indexedMsg:
+		m.handleIndexed(msg)
 		return m, nil
 
 	case configReloadedMsg:
 		m.config = msg.config
-		m.client = llm.NewOpenAIClient(
-			msg.config.APIKey,
-			msg.config.BaseURL,
-			msg.config.Model,
-			msg.config.Temperature,
-			msg.config.MaxTokens,
-		)
+		m.window = nil
+		m.client = newClient(msg.config, m.logger)
 		m.err = nil
+		if err := m.logger.SetVerbose(msg.config.Debug.Verbose); err != nil {
+			m.err = err
+		}
+		m.messageComp.SetCodeOptions(codeOptions(msg.config))
+		if m.compare != nil {
+			m.compare.renderer = nil
+		}
+		if !strings.EqualFold(msg.config.UI.Theme, m.theme.Name) {
+			if err := m.setTheme(msg.config.UI.Theme); err != nil {
+				m.err = err
+			}
+		}
+		m.resizeMessages()
+		if keys, err := keymap.New(msg.config.Keys); err != nil {
+			m.err = fmt.Errorf("invalid key bindings, keeping the previous ones:\n%w", err)
+		} else {
+			m.keys = keys
+			m.input.SetHistoryKeys(keys.Prev, keys.Next)
+		}
 		return m, nil
 	}
 
@@ -288,35 +632,129 @@ func (m *ChatModel) View() string {
 		return "Initializing..."
 	}
 
-	var view strings.Builder
+	window := m.contextWindow()
+	footer := m.renderFooter(window)
+	if m.fullscreen {
+		return m.renderFullscreen(window, footer)
+	}
+	// Finished messages are in the terminal scrollback; only the rest is redrawn
+	return m.renderTranscript(window, len(m.scrollback.printed)) + footer
+}
 
-	// Banner (always visible)
-	view.WriteString(RenderBanner(version.AppName, version.Description, version.Version, m.config.Model, m.config.BaseURL))
-	view.WriteString("\n\n")
+// renderBanner renders the banner above the conversation
+func (m *ChatModel) renderBanner() string {
+	return RenderBanner(version.AppName, version.Description, version.Version, m.config.Model, m.config.BaseURL, m.title, m.contentWidth()) + "\n\n"
+}
 
-	// Messages (render all, no height limit in inline mode)
-	for _, msg := range m.messages {
-		if msg.Role == "system" {
-			continue
-		}
-		view.WriteString(m.messageComp.RenderMessage(msg.Role, msg.Content))
-		view.WriteString("\n")
+// renderTranscript renders the messages from path index from on, followed by
+// the streaming reply
+func (m *ChatModel) renderTranscript(window *contextwin.Window, from int) string {
+	return m.renderMessages(window, from) + m.renderPending()
+}
+
+// renderMessages renders the messages from path index from on
+func (m *ChatModel) renderMessages(window *contextwin.Window, from int) string {
+	var view strings.Builder
+	for i := from; i < len(m.messages); i++ {
+		view.WriteString(m.renderMessage(window, i))
 	}
+	return view.String()
+}
 
-	// Render streaming content
-	if m.streaming && m.streamContent != "" {
-		view.WriteString(m.messageComp.RenderMessage("assistant", m.streamContent+" "+TypingStyle.Render("▊")))
+// renderPending renders what is still in progress below the messages: the
+// streaming reply, compare columns, retries or a summary
+func (m *ChatModel) renderPending() string {
+	var view strings.Builder
+	if m.compare != nil {
+		view.WriteString(m.renderCompare())
+	} else if m.retryBatch != nil {
+		view.WriteString(m.renderRetryProgress())
+		view.WriteString("\n")
+	} else if m.pendingSummary != nil {
+		view.WriteString(TypingStyle.Render("Summarizing earlier messages..."))
+		view.WriteString("\n")
+	} else if m.streaming && m.streamContent != "" {
+		view.WriteString(m.messageComp.RenderStreaming(m.streamContent + " " + TypingStyle.Render("▊")))
 	} else if m.streaming {
 		view.WriteString(m.messageComp.RenderTyping())
 		view.WriteString("\n")
 	}
 
+	return view.String()
+}
+
+// renderMessage renders message i of the path with its markers
+func (m *ChatModel) renderMessage(window *contextwin.Window, i int) string {
+	msg := m.messages[i]
+	if msg.Role == "system" && i != m.focusMessage {
+		return ""
+	}
+
+	var view strings.Builder
+	if i == m.focusMessage {
+		view.WriteString(SuccessStyle.Render("▶ search match"))
+		view.WriteString("\n")
+	}
+	if indicator := m.renderBranchIndicator(i); indicator != "" {
+		view.WriteString(indicator)
+		view.WriteString("\n")
+	}
+	if marker := renderContextMarker(window, i, msg.Pinned, msg.Summary); marker != "" {
+		view.WriteString(marker)
+		view.WriteString("\n")
+	}
+	rendered := m.messageComp.RenderMessage(msg.Role, msg.Content)
+	// Inline mode shows the selection in the selection bar instead
+	if m.fullscreen && m.selection != nil && i == m.selection.index {
+		rendered = SelectedMessageStyle.Render(strings.TrimRight(rendered, "\n")) + "\n"
+	}
+	view.WriteString(rendered)
+	view.WriteString("\n")
+	return view.String()
+}
+
+// renderFooter renders everything below the transcript: errors, prompts,
+// overlays, the input and the status bar
+func (m *ChatModel) renderFooter(window *contextwin.Window) string {
+	var view strings.Builder
+
 	// Error display
 	if m.err != nil {
 		view.WriteString(ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
 		view.WriteString("\n\n")
 	}
 
+	if m.notice != "" {
+		view.WriteString(SuccessStyle.Render(m.notice))
+		view.WriteString("\n")
+	}
+
+	view.WriteString(m.renderStatsPane())
+
+	// Confirmation prompt
+	if m.confirm != nil {
+		view.WriteString(m.renderConfirm())
+	}
+
+	// Picker overlay
+	if m.showKeys {
+		view.WriteString(m.renderKeys())
+	}
+	view.WriteString(m.renderReport())
+
+	if m.picker != nil {
+		view.WriteString(m.picker.View())
+		view.WriteString("\n")
+	}
+
+	view.WriteString(m.renderBranchBar())
+	view.WriteString(m.renderCodeFocus())
+
+	// Selection mode actions
+	if m.selection != nil {
+		view.WriteString(m.renderSelectionBar())
+	}
+
 	// Command suggestions
 	if len(m.suggestions) > 0 {
 		view.WriteString(m.renderSuggestions())
@@ -328,12 +766,21 @@ func (m *ChatModel) View() string {
 
 	// Bottom status bar: input mode + stats (on same line)
 	statusLine := m.input.GetModeIndicator()
+	if m.config.Context.Policy != contextwin.PolicyNone {
+		statusLine += "  " + renderContextGauge(window)
+	}
 	if m.stats.IsVisible() {
 		compactStats := m.stats.RenderCompactStats()
+		if m.streaming && m.live != nil {
+			compactStats = m.live.render(time.Now())
+		}
 		if compactStats != "" {
 			statusLine += "  " + compactStats
 		}
 	}
+	if indicator := m.renderScrollIndicator(); indicator != "" {
+		statusLine += "  " + indicator
+	}
 	if statusLine != "" {
 		view.WriteString("\n")
 		view.WriteString(statusLine)
@@ -342,50 +789,22 @@ func (m *ChatModel) View() string {
 	return view.String()
 }
 
-// renderMessages renders the message history
-func (m *ChatModel) renderMessages(maxHeight int) string {
-	var content strings.Builder
-
-	// Render all messages except system prompt
-	for _, msg := range m.messages {
-		if msg.Role == "system" {
-			continue
-		}
-		content.WriteString(m.messageComp.RenderMessage(msg.Role, msg.Content))
-		content.WriteString("\n")
-	}
-
-	// Render streaming content
-	if m.streaming && m.streamContent != "" {
-		// Use the same rendering style as completed messages
-		content.WriteString(m.messageComp.RenderMessage("assistant", m.streamContent+" "+TypingStyle.Render("▊")))
-		content.WriteString("\n")
-	} else if m.streaming {
-		content.WriteString(m.messageComp.RenderTyping())
-		content.WriteString("\n")
-	}
-
-	// Limit height and add scrolling effect
-	lines := strings.Split(content.String(), "\n")
-	if maxHeight > 0 && len(lines) > maxHeight {
-		lines = lines[len(lines)-maxHeight:]
-	}
-
-	return strings.Join(lines, "\n")
-}
-
 // streamResponse starts streaming a response
 func (m *ChatModel) streamResponse() tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		chunks, stats, err := m.client.ChatStream(ctx, m.messages)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelStream = cancel
+	client := m.client
+	messages := m.contextMessages()
+
+	return tea.Batch(func() tea.Msg {
+		chunks, stats, err := client.ChatStream(ctx, messages)
 		if err != nil {
 			return errorMsg{err: err}
 		}
 
 		// Store the channel and stats for reading chunks
 		return streamStartMsg{chunks: chunks, stats: stats}
-	}
+	}, m.startLiveStats())
 }
 
 type streamStartMsg struct {
@@ -398,13 +817,14 @@ func (m *ChatModel) waitForChunk() tea.Cmd {
 	if m.streamChan == nil {
 		return nil
 	}
+	source, stats := m.streamChan, m.streamStats
 
 	return func() tea.Msg {
-		chunk, ok := <-m.streamChan
+		chunk, ok := <-source
 		if !ok {
-			return streamCompleteMsg{stats: m.streamStats}
+			return streamCompleteMsg{source: source, stats: stats}
 		}
-		return streamChunkMsg{chunk: chunk}
+		return streamChunkMsg{source: source, chunk: chunk}
 	}
 }
 
@@ -420,21 +840,21 @@ func (m *ChatModel) handleCommand(input string) tea.Cmd {
 	case "help":
 		m.err = nil
 		// Display help as assistant message so it's visible
-		m.messages = append(m.messages, llm.Message{
-			Role:    "assistant",
-			Content: commands.CommandHelp(),
-		})
+		m.appendMessage(session.NewMessage("assistant", commands.CommandHelp(m.keys)))
 
 	case "new", "clear":
-		m.messages = []llm.Message{}
-		if m.systemPrompt != "" {
-			m.messages = append(m.messages, llm.Message{
-				Role:    "system",
-				Content: m.systemPrompt,
-			})
-		}
 		m.err = nil
-		m.streamContent = ""
+		if turns := m.userTurns(); turns > confirmClearTurns {
+			m.confirm = &confirmState{
+				prompt: fmt.Sprintf("Clear this conversation (%d turns)?", turns),
+				onYes: func() tea.Cmd {
+					m.newSession()
+					return nil
+				},
+			}
+		} else {
+			m.newSession()
+		}
 
 	case "reload":
 		return func() tea.Msg {
@@ -448,19 +868,32 @@ func (m *ChatModel) handleCommand(input string) tea.Cmd {
 	case "delete":
 		// Delete last turn (user message + assistant response)
 		if len(m.messages) >= 2 {
+			m.checkpoint("delete")
 			// Check if last message is from assistant
 			if m.messages[len(m.messages)-1].Role == "assistant" {
-				m.messages = m.messages[:len(m.messages)-2]
+				m.tree.Remove(m.path[len(m.path)-2])
 			} else {
-				m.messages = m.messages[:len(m.messages)-1]
+				m.tree.Remove(m.path[len(m.path)-1])
 			}
+			m.syncMessages()
 			m.err = nil
 		} else {
 			m.err = fmt.Errorf("no messages to delete")
 		}
 
 	case "stats":
-		m.stats.Toggle()
+		if err := cmd.ValidateArgs(0, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		switch {
+		case len(cmd.Args) == 0:
+			m.toggleStats(false)
+		case strings.ToLower(cmd.Args[0]) == "panel":
+			m.toggleStats(true)
+		default:
+			m.err = fmt.Errorf("unknown stats option %q (use /stats or /stats panel)", cmd.Args[0])
+		}
 
 	case "temp":
 		if err := cmd.ValidateArgs(1, 1); err != nil {
@@ -478,27 +911,42 @@ func (m *ChatModel) handleCommand(input string) tea.Cmd {
 		}
 		m.client.SetTemperature(temp)
 		m.err = nil
-		m.messages = append(m.messages, llm.Message{
-			Role:    "system",
-			Content: fmt.Sprintf("Temperature set to %.2f", temp),
-		})
+		m.appendMessage(session.NewMessage("system", fmt.Sprintf("Temperature set to %.2f", temp)))
 
 	case "system":
 		if err := cmd.ValidateArgs(1, 0); err != nil {
 			m.err = err
 			return nil
 		}
+		m.checkpoint("system prompt")
 		newPrompt := cmd.GetRestAsString(0)
 		m.systemPrompt = newPrompt
 		// Update system message if it exists
 		if len(m.messages) > 0 && m.messages[0].Role == "system" {
-			m.messages[0].Content = newPrompt
+			m.tree.Node(m.path[0]).Message.Content = newPrompt
 		} else {
-			m.messages = append([]llm.Message{{Role: "system", Content: newPrompt}}, m.messages...)
+			m.tree.InsertRoot(session.NewMessage("system", newPrompt))
 		}
+		m.syncMessages()
 		m.err = nil
 
 	case "copy":
+		if err := cmd.ValidateArgs(0, 2); err != nil {
+			m.err = err
+			return nil
+		}
+		if len(cmd.Args) > 0 {
+			if cmd.Args[0] != "code" {
+				m.err = fmt.Errorf("usage: /copy [code [n]]")
+				return nil
+			}
+			if err := m.copyCode(cmd.Args[1:]); err != nil {
+				m.err = err
+				return nil
+			}
+			m.err = nil
+			break
+		}
 		// Copy last assistant message
 		for i := len(m.messages) - 1; i >= 0; i-- {
 			if m.messages[i].Role == "assistant" {
@@ -506,15 +954,266 @@ func (m *ChatModel) handleCommand(input string) tea.Cmd {
 					m.err = fmt.Errorf("failed to copy: %w", err)
 				} else {
 					m.err = nil
-					m.messages = append(m.messages, llm.Message{
-						Role:    "system",
-						Content: "Last response copied to clipboard",
-					})
+					m.appendMessage(session.NewMessage("system", "Last response copied to clipboard"))
 				}
 				break
 			}
 		}
 
+	case "save-code":
+		if err := cmd.ValidateArgs(2, 2); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		if err := m.saveCode(cmd.Args); err != nil {
+			m.err = err
+			return nil
+		}
+
+	case "open":
+		if err := cmd.ValidateArgs(0, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		openCmd, err := m.openCode(cmd.Args)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.input.AddToHistory(input)
+		m.input.Reset()
+		return openCmd
+
+	case "debug":
+		if m.logger.Path() == "" {
+			m.err = fmt.Errorf("no log file configured (set debug.log_file)")
+			return nil
+		}
+		if err := m.logger.SetVerbose(!m.logger.Verbose()); err != nil {
+			m.err = err
+			return nil
+		}
+		m.config.Debug.Verbose = m.logger.Verbose()
+		m.err = nil
+		state := "disabled"
+		if m.logger.Verbose() {
+			state = "enabled"
+		}
+		m.notice = fmt.Sprintf("Debug logging %s • %s", state, m.logger.Path())
+
+	case "compare":
+		if err := cmd.ValidateArgs(1, maxCompareModels); err != nil {
+			m.err = err
+			return nil
+		}
+		if len(cmd.Args) == 1 && strings.ToLower(cmd.Args[0]) == "off" {
+			m.stopCompare()
+			m.err = nil
+			break
+		}
+		if err := m.StartCompare(cmd.Args); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+
+	case "pick":
+		if err := cmd.ValidateArgs(1, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		n, err := cmd.GetIntArg(0)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		if err := m.pickCompareWinner(n); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+
+	case "save":
+		if err := cmd.ValidateArgs(1, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		path := cmd.Args[0]
+		if err := session.Save(path, m.toSession()); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.notice = fmt.Sprintf("Conversation saved to %s", path)
+
+	case "load":
+		if err := cmd.ValidateArgs(1, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		s, err := session.Load(cmd.Args[0])
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.checkpointSession("load")
+		m.restoreSession(s)
+		m.err = nil
+
+	case "export":
+		path, err := m.exportConversation(cmd.Args)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.notice = fmt.Sprintf("Conversation exported to %s", path)
+
+	case "sessions":
+		if err := m.openSessionPicker(); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+
+	case "retry":
+		if err := cmd.ValidateArgs(0, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		if m.compare != nil {
+			m.err = fmt.Errorf("/retry is not available in compare mode")
+			return nil
+		}
+		n := 1
+		if len(cmd.Args) == 1 {
+			if n, err = cmd.GetIntArg(0); err != nil {
+				m.err = err
+				return nil
+			}
+		}
+		retryCmd, err := m.retry(n)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.input.AddToHistory(input)
+		m.input.Reset()
+		return retryCmd
+
+	case "edit":
+		if m.compare != nil {
+			m.err = fmt.Errorf("/edit is not available in compare mode")
+			return nil
+		}
+		editCmd, err := m.editMessage(cmd.Args)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.input.AddToHistory(input)
+		m.input.Reset()
+		return editCmd
+
+	case "tree":
+		if err := m.openTreePicker(); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+
+	case "find":
+		if err := cmd.ValidateArgs(1, 0); err != nil {
+			m.err = err
+			return nil
+		}
+		if err := m.openSearchPicker(cmd.GetRestAsString(0)); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+
+	case "context":
+		m.err = nil
+		m.report = m.contextReport()
+
+	case "pin":
+		if err := cmd.ValidateArgs(0, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		n := -1
+		if len(cmd.Args) == 1 {
+			if n, err = cmd.GetIntArg(0); err != nil {
+				m.err = err
+				return nil
+			}
+		}
+		index, err := m.resolveMessageIndex(n)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.togglePin(index)
+		m.err = nil
+
+	case "summarize":
+		summarizeCmd, err := m.summarize(false)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.input.AddToHistory(input)
+		m.input.Reset()
+		return summarizeCmd
+
+	case "title":
+		if err := cmd.ValidateArgs(1, 0); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		m.checkpoint("title")
+		m.setTitle(session.Truncate(cmd.GetRestAsString(0), maxTitleLength))
+
+	case "undo":
+		m.runUndo("Undid", m.undo)
+
+	case "redo":
+		m.runUndo("Redid", m.redo)
+
+	case "tokens":
+		m.err = nil
+		m.report = m.tokensReport()
+
+	case "cost":
+		m.err = nil
+		m.report = m.costReport()
+
+	case "keys":
+		m.showKeys = true
+
+	case "theme":
+		if err := cmd.ValidateArgs(0, 1); err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		if len(cmd.Args) == 0 {
+			m.report = m.themeReport()
+			break
+		}
+		if err := m.setTheme(cmd.Args[0]); err != nil {
+			m.err = err
+			return nil
+		}
+		m.notice = fmt.Sprintf("Theme set to %s", m.theme.Name)
+
 	case "multiline":
 		m.input.ToggleMultilineMode()
 
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	"github.com/alecthomas/chroma/lexers"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// codeOpenedMsg is sent when the editor showing a code block exits
+type codeOpenedMsg struct {
+	err error
+}
+
+// lastResponseBlocks returns the code blocks of the last assistant message
+func (m *ChatModel) lastResponseBlocks() ([]components.CodeBlock, error) {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role == "assistant" {
+			blocks := components.CodeBlocks(m.messages[i].Content)
+			if len(blocks) == 0 {
+				return nil, fmt.Errorf("the last response has no code blocks")
+			}
+			return blocks, nil
+		}
+	}
+	return nil, fmt.Errorf("no response yet")
+}
+
+// codeBlock returns block n (1-based) of the last response. Without n it is
+// the focused block, or the first one.
+func (m *ChatModel) codeBlock(args []string) (components.CodeBlock, int, error) {
+	blocks, err := m.lastResponseBlocks()
+	if err != nil {
+		return components.CodeBlock{}, 0, err
+	}
+
+	n := max(m.codeFocus, 1)
+	if len(args) > 0 {
+		if n, err = strconv.Atoi(args[0]); err != nil {
+			return components.CodeBlock{}, 0, fmt.Errorf("invalid code block number: %s", args[0])
+		}
+	}
+	if n < 1 || n > len(blocks) {
+		return components.CodeBlock{}, 0, fmt.Errorf("code block %d not found (%d blocks)", n, len(blocks))
+	}
+	return blocks[n-1], n, nil
+}
+
+// cycleCodeFocus focuses the next code block of the last response, wrapping
+// around to no focus after the last one
+func (m *ChatModel) cycleCodeFocus() error {
+	blocks, err := m.lastResponseBlocks()
+	if err != nil {
+		return err
+	}
+	m.codeFocus = (m.codeFocus + 1) % (len(blocks) + 1)
+	return nil
+}
+
+// copyCode copies a code block of the last response for /copy code [n]
+func (m *ChatModel) copyCode(args []string) error {
+	block, n, err := m.codeBlock(args)
+	if err != nil {
+		return err
+	}
+	m.notice = m.copyToClipboard(block.Code, fmt.Sprintf("Code block %d copied", n))
+	return nil
+}
+
+// saveCode writes a code block of the last response to path for
+// /save-code n <path>, adding an extension from its language if path has
+// none. An existing file is only replaced once the user confirms.
+func (m *ChatModel) saveCode(args []string) error {
+	block, _, err := m.codeBlock(args[:1])
+	if err != nil {
+		return err
+	}
+
+	path := args[1]
+	if filepath.Ext(path) == "" {
+		path += codeExtension(block.Language)
+	}
+	err = writeCode(path, block.Code, false)
+	if os.IsExist(err) {
+		m.confirm = &confirmState{
+			prompt: fmt.Sprintf("Overwrite %s?", path),
+			onYes: func() tea.Cmd {
+				if err := writeCode(path, block.Code, true); err != nil {
+					m.err = err
+					return nil
+				}
+				m.notice = fmt.Sprintf("Code saved to %s", path)
+				return nil
+			},
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m.notice = fmt.Sprintf("Code saved to %s", path)
+	return nil
+}
+
+// writeCode writes code to path, failing with an os.ErrExist error if the
+// file exists unless overwrite is set
+func writeCode(path, code string, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if os.IsExist(err) {
+		return err
+	}
+	if err == nil {
+		_, err = f.WriteString(code)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save code: %w", err)
+	}
+	return nil
+}
+
+// openCode opens a code block of the last response in $EDITOR for /open n
+func (m *ChatModel) openCode(args []string) (tea.Cmd, error) {
+	block, _, err := m.codeBlock(args)
+	if err != nil {
+		return nil, err
+	}
+	return openInEditor(block.Code, codeExtension(block.Language), func(_ string, err error) tea.Msg {
+		return codeOpenedMsg{err: err}
+	}), nil
+}
+
+// codeExtension returns the file extension for a language tag, e.g. ".py"
+// for "python", or ".txt" if unknown
+func codeExtension(language string) string {
+	if lexer := lexers.Get(language); language != "" && lexer != nil {
+		for _, pattern := range lexer.Config().Filenames {
+			if ext := filepath.Ext(pattern); strings.HasPrefix(pattern, "*.") && ext != "" {
+				return ext
+			}
+		}
+	}
+	return ".txt"
+}
+
+// renderCodeFocus describes the focused code block and its actions
+func (m *ChatModel) renderCodeFocus() string {
+	if m.codeFocus == 0 {
+		return ""
+	}
+	blocks, err := m.lastResponseBlocks()
+	if err != nil || m.codeFocus > len(blocks) {
+		return ""
+	}
+
+	block := blocks[m.codeFocus-1]
+	language := block.Language
+	if language == "" {
+		language = "code"
+	}
+	lines := strings.Count(strings.TrimSuffix(block.Code, "\n"), "\n") + 1
+	return CommandStyle.Render(fmt.Sprintf("Code block %d/%d", m.codeFocus, len(blocks))) +
+		HelpStyle.Render(fmt.Sprintf("%s, %d lines • /copy code • /save-code %d <path> • /open %d • %s next",
+			language, lines, m.codeFocus, m.codeFocus, m.keys.CodeFocus.Help().Key)) + "\n"
+}
@@ -0,0 +1,368 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/keymap"
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	minCompareModels = 2
+	maxCompareModels = 3
+	compareGap       = 2
+)
+
+// compareColumn holds the state of one model in compare mode
+type compareColumn struct {
+	model   string
+	baseURL string
+	client  llm.Client
+	content string
+	chunks  <-chan llm.StreamChunk
+	stats   *components.StatsComponent
+	request *llm.RequestStats
+	done    bool
+	err     error
+}
+
+// compareRound is one question sent to every model. Stream messages carry
+// the round they belong to, so those of a cancelled round are ignored.
+type compareRound struct {
+	cancel context.CancelFunc
+}
+
+// compareState holds the side-by-side comparison of several models
+type compareState struct {
+	columns      []*compareColumn
+	round        *compareRound
+	awaitingPick bool
+	renderer     *components.MessageComponent
+	renderWidth  int
+}
+
+// Messages for compare mode streams
+type compareStartMsg struct {
+	round  *compareRound
+	index  int
+	chunks <-chan llm.StreamChunk
+	stats  *llm.RequestStats
+	err    error
+}
+
+type compareChunkMsg struct {
+	round  *compareRound
+	index  int
+	chunk  llm.StreamChunk
+	closed bool
+}
+
+// parseCompareTarget splits "model" or "model@base-url" into its parts
+func parseCompareTarget(target, defaultBaseURL string) (string, string) {
+	model, baseURL, found := strings.Cut(target, "@")
+	if !found || baseURL == "" {
+		return target, defaultBaseURL
+	}
+	return model, baseURL
+}
+
+// StartCompare enables compare mode for the given models (or model@base-url endpoints)
+func (m *ChatModel) StartCompare(targets []string) error {
+	if len(targets) < minCompareModels || len(targets) > maxCompareModels {
+		return fmt.Errorf("compare needs %d to %d models", minCompareModels, maxCompareModels)
+	}
+
+	state := &compareState{}
+	for _, target := range targets {
+		model, baseURL := parseCompareTarget(target, m.config.BaseURL)
+		cfg := *m.config
+		cfg.Model = model
+		cfg.BaseURL = baseURL
+		cfg.Temperature = m.client.GetTemperature()
+
+		state.columns = append(state.columns, &compareColumn{
+			model:   model,
+			baseURL: baseURL,
+			client:  newClient(&cfg, m.logger),
+			stats:   components.NewStatsComponent(),
+		})
+	}
+
+	m.compare = state
+	return nil
+}
+
+// stopCompare leaves compare mode, cancelling the requests still running
+func (m *ChatModel) stopCompare() {
+	if m.compare != nil {
+		m.compare.cancelRound()
+	}
+	m.compare = nil
+}
+
+// cancelRound cancels the requests of the current round
+func (s *compareState) cancelRound() {
+	if s.round != nil {
+		s.round.cancel()
+		s.round = nil
+	}
+}
+
+// streamCompare sends the current conversation to every model concurrently
+func (m *ChatModel) streamCompare() tea.Cmd {
+	messages := m.contextMessages()
+
+	m.compare.cancelRound()
+	ctx, cancel := context.WithCancel(context.Background())
+	round := &compareRound{cancel: cancel}
+	m.compare.round = round
+
+	var cmds []tea.Cmd
+	for i, col := range m.compare.columns {
+		col.content = ""
+		col.chunks = nil
+		col.request = nil
+		col.done = false
+		col.err = nil
+		col.client.SetTemperature(m.client.GetTemperature())
+
+		index, client := i, col.client
+		cmds = append(cmds, func() tea.Msg {
+			chunks, stats, err := client.ChatStream(ctx, messages)
+			return compareStartMsg{round: round, index: index, chunks: chunks, stats: stats, err: err}
+		})
+	}
+	m.compare.awaitingPick = false
+	return tea.Batch(cmds...)
+}
+
+// waitForCompareChunk waits for the next chunk of one column
+func (m *ChatModel) waitForCompareChunk(index int) tea.Cmd {
+	if m.compare == nil || index >= len(m.compare.columns) {
+		return nil
+	}
+	chunks := m.compare.columns[index].chunks
+	if chunks == nil {
+		return nil
+	}
+
+	round := m.compare.round
+	return func() tea.Msg {
+		chunk, ok := <-chunks
+		if !ok {
+			return compareChunkMsg{round: round, index: index, closed: true}
+		}
+		return compareChunkMsg{round: round, index: index, chunk: chunk}
+	}
+}
+
+// updateCompare handles compare mode stream messages
+func (m *ChatModel) updateCompare(msg tea.Msg) tea.Cmd {
+	if m.compare == nil {
+		return nil
+	}
+
+	switch msg := msg.(type) {
+	case compareStartMsg:
+		col := m.compareColumn(msg.round, msg.index)
+		if col == nil {
+			return nil
+		}
+		if msg.err != nil {
+			col.err = msg.err
+			col.done = true
+			m.finishCompareColumn()
+			return nil
+		}
+		col.chunks = msg.chunks
+		col.request = msg.stats
+		return m.waitForCompareChunk(msg.index)
+
+	case compareChunkMsg:
+		col := m.compareColumn(msg.round, msg.index)
+		if col == nil || col.done {
+			return nil
+		}
+		if msg.chunk.Error != nil {
+			col.err = msg.chunk.Error
+		}
+		if msg.closed || msg.chunk.Done || msg.chunk.Error != nil {
+			col.done = true
+			col.chunks = nil
+			// Every column is paid for, whichever answer is picked
+			m.logRequest(col.request)
+			col.stats.SetStats(col.request)
+			m.finishCompareColumn()
+			return nil
+		}
+		col.content += msg.chunk.Content
+		return m.waitForCompareChunk(msg.index)
+	}
+
+	return nil
+}
+
+// compareColumn returns the column a stream message is for, or nil if the
+// message belongs to an earlier round
+func (m *ChatModel) compareColumn(round *compareRound, index int) *compareColumn {
+	if round == nil || round != m.compare.round || index < 0 || index >= len(m.compare.columns) {
+		return nil
+	}
+	return m.compare.columns[index]
+}
+
+// cancelCompare cancels the columns that are still streaming
+func (m *ChatModel) cancelCompare() {
+	m.compare.cancelRound()
+	for _, col := range m.compare.columns {
+		if !col.done {
+			col.done = true
+			col.chunks = nil
+			col.err = fmt.Errorf("cancelled")
+		}
+	}
+	m.finishCompareColumn()
+}
+
+// finishCompareColumn ends streaming once every column is done
+func (m *ChatModel) finishCompareColumn() {
+	for _, col := range m.compare.columns {
+		if !col.done {
+			return
+		}
+	}
+	m.compare.cancelRound()
+	m.streaming = false
+	m.compare.awaitingPick = true
+}
+
+// pickCompareWinner continues the conversation with the answer of column n (1-based)
+func (m *ChatModel) pickCompareWinner(n int) error {
+	if m.compare == nil {
+		return fmt.Errorf("not in compare mode (use /compare <model-a> <model-b>)")
+	}
+	if !m.compare.awaitingPick {
+		return fmt.Errorf("no answers to pick from yet")
+	}
+	if n < 1 || n > len(m.compare.columns) {
+		return fmt.Errorf("pick a column between 1 and %d", len(m.compare.columns))
+	}
+
+	col := m.compare.columns[n-1]
+	if col.err != nil || col.content == "" {
+		return fmt.Errorf("column %d has no answer to pick", n)
+	}
+
+	m.stats.SetStats(col.request)
+	reply := session.NewMessage("assistant", col.content)
+	reply.Stats = col.request
+	m.appendMessage(reply)
+	m.autosave()
+
+	// Continue with the winning model and endpoint
+	col.client.SetTemperature(m.client.GetTemperature())
+	m.client = col.client
+	m.config.Model = col.model
+	m.config.BaseURL = col.baseURL
+
+	m.compare.awaitingPick = false
+	for _, c := range m.compare.columns {
+		c.content = ""
+		c.done = false
+	}
+	return nil
+}
+
+// renderCompare renders the model columns side by side
+func (m *ChatModel) renderCompare() string {
+	if m.compare == nil {
+		return ""
+	}
+
+	n := len(m.compare.columns)
+	if !m.streaming && !m.compare.awaitingPick {
+		var models []string
+		for i, col := range m.compare.columns {
+			models = append(models, fmt.Sprintf("[%d] %s", i+1, col.model))
+		}
+		return HelpStyle.Render("Compare mode: "+strings.Join(models, " • ")+" (/compare off to stop)") + "\n"
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = defaultWidth
+	}
+	colWidth := (width - compareGap*(n-1)) / n
+	if colWidth < 20 {
+		colWidth = 20
+	}
+
+	if m.compare.renderer == nil || m.compare.renderWidth != colWidth {
+		renderer, err := components.NewMessageComponent(colWidth, m.theme, codeOptions(m.config))
+		if err == nil {
+			m.compare.renderer = renderer
+			m.compare.renderWidth = colWidth
+		}
+	}
+
+	columnStyle := lipgloss.NewStyle().Width(colWidth).MarginRight(compareGap)
+
+	var rendered []string
+	for i, col := range m.compare.columns {
+		var content strings.Builder
+
+		header := fmt.Sprintf("[%d] %s", i+1, col.model)
+		content.WriteString(CommandStyle.Render(header))
+		content.WriteString("\n")
+		content.WriteString(DividerStyle.Render(strings.Repeat("─", colWidth)))
+		content.WriteString("\n")
+
+		switch {
+		case col.err != nil:
+			content.WriteString(ErrorStyle.Width(colWidth).Render(fmt.Sprintf("Error: %v", col.err)))
+		case col.content == "" && !col.done:
+			content.WriteString(m.messageComp.RenderTyping())
+		default:
+			switch {
+			case m.compare.renderer == nil:
+				content.WriteString(col.content)
+			case col.done:
+				content.WriteString(m.compare.renderer.RenderMessage("assistant", col.content))
+			default:
+				content.WriteString(m.compare.renderer.RenderStreaming(col.content + " " + TypingStyle.Render("▊")))
+			}
+		}
+
+		if col.done {
+			content.WriteString("\n")
+			content.WriteString(col.stats.RenderCompactStats())
+		}
+
+		style := columnStyle
+		if i == n-1 {
+			style = style.MarginRight(0)
+		}
+		rendered = append(rendered, style.Render(content.String()))
+	}
+
+	view := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	if m.compare.awaitingPick {
+		view += "\n" + HelpStyle.Render(fmt.Sprintf("Press %s to continue with an answer • /compare off to leave compare mode", m.comparePickHint()))
+	}
+	return view + "\n"
+}
+
+// comparePickHint names the ways to pick a column, e.g. "1/2 or /pick <n>"
+func (m *ChatModel) comparePickHint() string {
+	keys := m.keys.ComparePick.Keys()
+	if len(keys) == 0 {
+		return "/pick <n>"
+	}
+	return keymap.Describe(keys[:min(len(keys), len(m.compare.columns))]) + " or /pick <n>"
+}
@@ -0,0 +1,281 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// codeIndent aligns code blocks with the markdown margin
+	codeIndent = 2
+	// tabWidth is the number of spaces a tab expands to
+	tabWidth = 4
+)
+
+// openingFence matches the line opening a fenced code block: its indent, the
+// fence and the info string
+var openingFence = regexp.MustCompile("^( *)(`{3,}|~{3,})\\s*([^`\\s]*)")
+
+// listItem matches a list item marker with the spaces after it
+var listItem = regexp.MustCompile(`^ *([-*+]|\d{1,9}[.)])( +|$)`)
+
+// CodeOptions controls how fenced code blocks are drawn
+type CodeOptions struct {
+	Highlight   bool // syntax highlighting
+	LineNumbers bool
+	Wrap        bool // soft-wrap long lines instead of truncating them
+}
+
+// CodeBlockComponent renders fenced code blocks with a language label, line
+// numbers and chroma highlighting, fitting long lines to the width
+type CodeBlockComponent struct {
+	width   int
+	style   *chroma.Style
+	options CodeOptions
+}
+
+// NewCodeBlockComponent creates a code block component using the chroma style
+// called style
+func NewCodeBlockComponent(width int, style string, options CodeOptions) *CodeBlockComponent {
+	return &CodeBlockComponent{
+		width:   width,
+		style:   styles.Get(style),
+		options: options,
+	}
+}
+
+// CodeBlock is a fenced code block of a message
+type CodeBlock struct {
+	Language string
+	Code     string
+}
+
+// CodeBlocks returns the fenced code blocks of markdown content, including
+// those in list items, in order, numbered from 1 when rendered
+func CodeBlocks(content string) []CodeBlock {
+	var blocks []CodeBlock
+	for _, segment := range splitFences(content) {
+		if segment.fenced {
+			blocks = append(blocks, CodeBlock{Language: segment.language, Code: segment.text})
+		}
+	}
+	return blocks
+}
+
+// Render renders code written in language, which may be empty. A positive
+// number labels the block for /copy code and friends; indent shifts blocks
+// nested in list items right.
+func (c *CodeBlockComponent) Render(number, indent int, language, code string) string {
+	lines := c.highlight(language, code)
+
+	gutter := 0
+	if c.options.LineNumbers {
+		gutter = len(fmt.Sprint(len(lines)))
+	}
+	available := c.width - codeIndent - indent
+	if gutter > 0 {
+		available -= gutter + 3 // "12 │ "
+	}
+	available = max(available, 10)
+
+	label := language
+	if label == "" {
+		label = "code"
+	}
+	if number > 0 {
+		label = fmt.Sprintf("[%d] %s", number, label)
+	}
+
+	var b strings.Builder
+	b.WriteString(codeLabelStyle.Render(label))
+	for i, line := range lines {
+		parts := []string{line}
+		if ansi.StringWidth(line) > available {
+			if c.options.Wrap {
+				parts = strings.Split(ansi.Hardwrap(line, available, true), "\n")
+			} else {
+				parts = []string{ansi.Truncate(line, available, "…")}
+			}
+		}
+
+		for j, part := range parts {
+			b.WriteString("\n")
+			switch {
+			case gutter == 0:
+			case j == 0:
+				b.WriteString(codeGutterStyle.Render(fmt.Sprintf("%*d │ ", gutter, i+1)))
+			default:
+				b.WriteString(codeGutterStyle.Render(strings.Repeat(" ", gutter) + " ↪ "))
+			}
+			b.WriteString(part)
+		}
+	}
+
+	return lipgloss.NewStyle().MarginLeft(codeIndent + indent).Render(b.String())
+}
+
+// highlight splits code into lines, colored unless highlighting is off
+func (c *CodeBlockComponent) highlight(language, code string) []string {
+	code = strings.TrimSuffix(strings.ReplaceAll(code, "\t", strings.Repeat(" ", tabWidth)), "\n")
+	if !c.options.Highlight {
+		return strings.Split(code, "\n")
+	}
+
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return strings.Split(code, "\n")
+	}
+
+	lines := []string{""}
+	for token := iterator(); token != chroma.EOF; token = iterator() {
+		style := c.tokenStyle(token.Type)
+		for i, piece := range strings.Split(token.Value, "\n") {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			if piece != "" {
+				lines[len(lines)-1] += style.Render(piece)
+			}
+		}
+	}
+	// Lexers end the code with a newline
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// tokenStyle converts the chroma style of a token type to lipgloss
+func (c *CodeBlockComponent) tokenStyle(t chroma.TokenType) lipgloss.Style {
+	entry := c.style.Get(t)
+	style := lipgloss.NewStyle()
+	if entry.Colour.IsSet() {
+		style = style.Foreground(lipgloss.Color(entry.Colour.String()))
+	}
+	if entry.Bold == chroma.Yes {
+		style = style.Bold(true)
+	}
+	if entry.Italic == chroma.Yes {
+		style = style.Italic(true)
+	}
+	if entry.Underline == chroma.Yes {
+		style = style.Underline(true)
+	}
+	return style
+}
+
+// markdownSegment is a run of markdown text or a fenced code block
+type markdownSegment struct {
+	text     string
+	language string
+	fenced   bool
+	indent   int // content column of the list item holding a code block
+}
+
+// splitFences splits markdown into text and fenced code blocks, including
+// those nested in list items. Code lines lose the indent of their opening
+// fence, as in CommonMark. A fence left open, as while a reply is streaming,
+// runs to the end.
+func splitFences(content string) []markdownSegment {
+	var segments []markdownSegment
+	var text, code strings.Builder
+	var fence, language string
+	var lists []int // content columns of the list items the current line is in
+	fenceIndent, base, dedent := 0, 0, 0
+	inCode := false
+
+	closeCode := func() {
+		segments = append(segments, markdownSegment{text: code.String(), language: language, fenced: true, indent: base})
+		code.Reset()
+		inCode = false
+		// Text after a fence in a list item would otherwise read as indented code
+		dedent = base
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		indent := len(trimmed) - len(strings.TrimLeft(trimmed, " "))
+		blank := strings.TrimSpace(trimmed) == ""
+
+		if inCode {
+			// A closing fence uses the same character, at least as many times
+			closing := strings.TrimSpace(trimmed)
+			if !blank && indent >= base && indent-base <= 3 &&
+				len(closing) >= len(fence) && strings.Trim(closing, fence[:1]) == "" {
+				closeCode()
+				continue
+			}
+			// Leaving the list item also ends its code block
+			if blank || indent >= base {
+				code.WriteString(trimIndent(line, fenceIndent))
+				continue
+			}
+			closeCode()
+		}
+
+		if !blank {
+			lists = openLists(lists, trimmed, indent)
+			if indent < dedent {
+				dedent = 0
+			}
+		}
+		base = 0
+		if len(lists) > 0 {
+			base = lists[len(lists)-1]
+		}
+		if match := openingFence.FindStringSubmatch(trimmed); match != nil && indent-base <= 3 {
+			if text.Len() > 0 {
+				segments = append(segments, markdownSegment{text: text.String()})
+				text.Reset()
+			}
+			inCode, fence, language, fenceIndent = true, match[2], match[3], indent
+			continue
+		}
+		text.WriteString(trimIndent(line, dedent))
+	}
+
+	if inCode {
+		segments = append(segments, markdownSegment{text: code.String(), language: language, fenced: true, indent: base})
+	} else if text.Len() > 0 {
+		segments = append(segments, markdownSegment{text: text.String()})
+	}
+	return segments
+}
+
+// openLists returns the content columns of the list items a non-blank line
+// with the given indent is in, closing those it is outdented from
+func openLists(lists []int, line string, indent int) []int {
+	for len(lists) > 0 && lists[len(lists)-1] > indent {
+		lists = lists[:len(lists)-1]
+	}
+	if match := listItem.FindStringSubmatch(line); match != nil {
+		column := len(match[0])
+		if match[2] == "" {
+			column++
+		}
+		lists = append(lists, column)
+	}
+	return lists
+}
+
+// trimIndent removes up to n leading spaces from line
+func trimIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
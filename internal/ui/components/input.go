@@ -1,13 +1,11 @@
 package components
 
 import (
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
-var helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Italic(true)
-
 // InputComponent handles user input
 type InputComponent struct {
 	textarea      textarea.Model
@@ -15,6 +13,8 @@ type InputComponent struct {
 	history       []string
 	historyIndex  int
 	currentInput  string
+	prevKey       key.Binding
+	nextKey       key.Binding
 }
 
 // NewInputComponent creates a new input component
@@ -33,9 +33,17 @@ func NewInputComponent() *InputComponent {
 		history:       []string{},
 		historyIndex:  -1,
 		currentInput:  "",
+		prevKey:       key.NewBinding(key.WithKeys("up")),
+		nextKey:       key.NewBinding(key.WithKeys("down")),
 	}
 }
 
+// SetHistoryKeys sets the keys that recall the previous and next history entry
+func (i *InputComponent) SetHistoryKeys(prev, next key.Binding) {
+	i.prevKey = prev
+	i.nextKey = next
+}
+
 // Init initializes the component
 func (i *InputComponent) Init() tea.Cmd {
 	return textarea.Blink
@@ -47,8 +55,8 @@ func (i *InputComponent) Update(msg tea.Msg) (*InputComponent, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "up":
+		switch {
+		case key.Matches(msg, i.prevKey):
 			if len(i.history) > 0 {
 				if i.historyIndex == -1 {
 					// Save current input before navigating history
@@ -61,7 +69,7 @@ func (i *InputComponent) Update(msg tea.Msg) (*InputComponent, tea.Cmd) {
 				i.textarea.SetValue(i.history[len(i.history)-1-i.historyIndex])
 			}
 			return i, nil
-		case "down":
+		case key.Matches(msg, i.nextKey):
 			if i.historyIndex >= 0 {
 				i.historyIndex--
 				if i.historyIndex == -1 {
@@ -1,81 +1,138 @@
 package components
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/LETHEVIET/chat-tui/internal/theme"
 	"github.com/charmbracelet/glamour"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
-var (
-	userMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("86"))
-
-	assistantMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("212"))
-
-	systemMessageStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("240")).
-				Italic(true)
-
-	typingStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			Italic(true)
+const (
+	// maxCachedMessages bounds the render cache, which is cleared when full
+	maxCachedMessages = 1000
+	// wrapMargin keeps rendered lines clear of the right edge of the terminal
+	wrapMargin = 2
+	// minWrapWidth stops very narrow terminals from wrapping every word
+	minWrapWidth = 20
 )
 
+// renderKey identifies a rendered message in the cache
+type renderKey struct {
+	role    string
+	content string
+	width   int
+}
+
 // MessageComponent handles rendering of chat messages
 type MessageComponent struct {
+	cache           map[renderKey]string
 	glamourRenderer *glamour.TermRenderer
+	code            *CodeBlockComponent
+	codeOptions     CodeOptions
+	theme           *theme.Theme
+	width           int
 }
 
-// NewMessageComponent creates a new message component
-func NewMessageComponent(width int) (*MessageComponent, error) {
+// NewMessageComponent creates a new message component rendering markdown in
+// the style of t
+func NewMessageComponent(width int, t *theme.Theme, code CodeOptions) (*MessageComponent, error) {
+	m := &MessageComponent{width: width, codeOptions: code}
+	if err := m.SetTheme(t); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetTheme switches the markdown and code styles to the ones of t
+func (m *MessageComponent) SetTheme(t *theme.Theme) error {
 	r, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(width-10),
+		t.GlamourOption(),
+		glamour.WithWordWrap(m.wrapWidth()),
 	)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to load markdown style of theme %q: %w", t.Name, err)
 	}
 
-	return &MessageComponent{
-		glamourRenderer: r,
-	}, nil
+	m.glamourRenderer = r
+	m.theme = t
+	m.code = NewCodeBlockComponent(m.wrapWidth(), t.Chroma, m.codeOptions)
+	m.cache = nil
+	return nil
 }
 
-// RenderMessage renders a single message with proper formatting
+// SetCodeOptions changes how code blocks are drawn
+func (m *MessageComponent) SetCodeOptions(code CodeOptions) {
+	m.codeOptions = code
+	m.code = NewCodeBlockComponent(m.wrapWidth(), m.theme.Chroma, code)
+	m.cache = nil
+}
+
+// SetWidth rewraps messages to width columns
+func (m *MessageComponent) SetWidth(width int) error {
+	if width == m.width {
+		return nil
+	}
+	m.width = width
+	return m.SetTheme(m.theme)
+}
+
+// wrapWidth returns the column at which text and code are wrapped
+func (m *MessageComponent) wrapWidth() int {
+	return max(minWrapWidth, m.width-wrapMargin)
+}
+
+// RenderMessage renders a single message with proper formatting. Results are
+// cached until the width, the theme or the code options change.
 func (m *MessageComponent) RenderMessage(role, content string) string {
+	key := renderKey{role: role, content: content, width: m.width}
+	if rendered, ok := m.cache[key]; ok {
+		return rendered
+	}
+
+	rendered := m.render(role, content)
+	if m.cache == nil || len(m.cache) >= maxCachedMessages {
+		m.cache = make(map[renderKey]string)
+	}
+	m.cache[key] = rendered
+	return rendered
+}
+
+// RenderStreaming renders an assistant reply that is still streaming. It
+// changes with every chunk, so it is not cached.
+func (m *MessageComponent) RenderStreaming(content string) string {
+	return m.render("assistant", content)
+}
+
+// render renders a message with the formatting of its role
+func (m *MessageComponent) render(role, content string) string {
 	switch role {
 	case "user":
 		// User messages: render as plain text with ">" prefix (no markdown)
-		lines := strings.Split(content, "\n")
-		for i, line := range lines {
+		var lines []string
+		for _, line := range strings.Split(content, "\n") {
 			if strings.TrimSpace(line) == "" {
-				lines[i] = userMessageStyle.Render(">")
-			} else {
-				lines[i] = userMessageStyle.Render("> ") + line
+				lines = append(lines, userMessageStyle.Render(">"))
+				continue
+			}
+			for _, row := range strings.Split(ansi.Wrap(line, m.wrapWidth()-2, ""), "\n") {
+				lines = append(lines, userMessageStyle.Render("> ")+row)
 			}
 		}
 		return strings.Join(lines, "\n")
 
 	case "assistant":
-		// Assistant messages: render with markdown, no prefix
-		rendered, err := m.glamourRenderer.Render(content)
-		if err != nil {
-			// Fallback to plain text if markdown rendering fails
-			rendered = content
-		}
-		// Remove trailing newlines
-		rendered = strings.TrimRight(rendered, "\n")
-		return rendered + "\n"
+		// Assistant messages: render with markdown and numbered code blocks, no prefix
+		return m.renderMarkdown(content, true) + "\n"
+
+	case "report":
+		// Command output shown outside the conversation: markdown, no label
+		return m.renderMarkdown(content, false)
 
 	case "system":
 		// System messages: render with label
-		rendered, err := m.glamourRenderer.Render(content)
-		if err != nil {
-			rendered = content
-		}
-		rendered = strings.TrimRight(rendered, "\n")
+		rendered := m.renderMarkdown(content, false)
 		headerLine := systemMessageStyle.Render("System:")
 		return headerLine + "\n" + rendered + "\n"
 
@@ -84,6 +141,49 @@ func (m *MessageComponent) RenderMessage(role, content string) string {
 	}
 }
 
+// renderMarkdown renders markdown with glamour, drawing fenced code blocks with
+// the code block component
+func (m *MessageComponent) renderMarkdown(content string, numbered bool) string {
+	var blocks []string
+	number := 0
+	for _, segment := range splitFences(content) {
+		if segment.fenced {
+			if numbered {
+				number++
+			}
+			blocks = append(blocks, m.code.Render(number, segment.indent, segment.language, segment.text))
+			continue
+		}
+		if strings.TrimSpace(segment.text) == "" {
+			continue
+		}
+		rendered, err := m.glamourRenderer.Render(segment.text)
+		if err != nil {
+			// Fallback to plain text if markdown rendering fails
+			rendered = segment.text
+		}
+		blocks = append(blocks, strings.Trim(rendered, "\n"))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// RenderPlain renders markdown as plain text without styling, for copying
+func RenderPlain(content string) (string, error) {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle("notty"),
+		glamour.WithWordWrap(0),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	rendered, err := r.Render(content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(rendered), nil
+}
+
 // RenderTyping renders a typing indicator
 func (m *MessageComponent) RenderTyping() string {
 	return typingStyle.Render("typing...")
@@ -0,0 +1,114 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PickerItem is one entry of a picker list
+type PickerItem struct {
+	Key    string
+	Label  string
+	Detail string
+}
+
+// PickerComponent is a scrollable list to choose an item from
+type PickerComponent struct {
+	title    string
+	items    []PickerItem
+	selected int
+	offset   int
+	height   int
+}
+
+// NewPickerComponent creates a picker showing at most height items at once
+func NewPickerComponent(title string, items []PickerItem, height int) *PickerComponent {
+	if height < 1 {
+		height = 10
+	}
+	return &PickerComponent{
+		title:  title,
+		items:  items,
+		height: height,
+	}
+}
+
+// MoveUp selects the previous item
+func (p *PickerComponent) MoveUp() {
+	if p.selected > 0 {
+		p.selected--
+	}
+	if p.selected < p.offset {
+		p.offset = p.selected
+	}
+}
+
+// MoveDown selects the next item
+func (p *PickerComponent) MoveDown() {
+	if p.selected < len(p.items)-1 {
+		p.selected++
+	}
+	if p.selected >= p.offset+p.height {
+		p.offset = p.selected - p.height + 1
+	}
+}
+
+// SetSelected selects the item at index and scrolls it into view
+func (p *PickerComponent) SetSelected(index int) {
+	if index < 0 || index >= len(p.items) {
+		return
+	}
+	p.selected = index
+	if p.selected < p.offset {
+		p.offset = p.selected
+	} else if p.selected >= p.offset+p.height {
+		p.offset = p.selected - p.height + 1
+	}
+}
+
+// Selected returns the selected item
+func (p *PickerComponent) Selected() (PickerItem, bool) {
+	if len(p.items) == 0 {
+		return PickerItem{}, false
+	}
+	return p.items[p.selected], true
+}
+
+// View renders the picker
+func (p *PickerComponent) View() string {
+	var view strings.Builder
+
+	view.WriteString(pickerTitleStyle.Render(p.title))
+	view.WriteString("\n")
+
+	if len(p.items) == 0 {
+		view.WriteString(pickerDetailStyle.Render("  nothing to show"))
+		view.WriteString("\n")
+	}
+
+	end := p.offset + p.height
+	if end > len(p.items) {
+		end = len(p.items)
+	}
+	for i := p.offset; i < end; i++ {
+		item := p.items[i]
+		if i == p.selected {
+			view.WriteString(pickerSelectedStyle.Render("▸ " + item.Label))
+		} else {
+			view.WriteString(pickerItemStyle.Render("  " + item.Label))
+		}
+		if item.Detail != "" {
+			view.WriteString("  " + pickerDetailStyle.Render(item.Detail))
+		}
+		view.WriteString("\n")
+	}
+
+	if len(p.items) > p.height {
+		view.WriteString(pickerDetailStyle.Render(fmt.Sprintf("  %d/%d", p.selected+1, len(p.items))))
+		view.WriteString("\n")
+	}
+	view.WriteString(pickerDetailStyle.Render("↑↓ to navigate • Enter to open • Esc to cancel"))
+	view.WriteString("\n")
+
+	return view.String()
+}
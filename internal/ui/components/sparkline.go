@@ -0,0 +1,25 @@
+package components
+
+import "github.com/LETHEVIET/chat-tui/internal/usage"
+
+// sparkBars are the bar heights of a sparkline, lowest first
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the last width values as a bar chart scaled between their
+// minimum and maximum
+func Sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	lo, hi := usage.MinMax(values)
+
+	bars := make([]rune, len(values))
+	for i, v := range values {
+		level := len(sparkBars) / 2
+		if hi > lo {
+			level = int((v-lo)/(hi-lo)*float64(len(sparkBars)-1) + 0.5)
+		}
+		bars[i] = sparkBars[level]
+	}
+	return string(bars)
+}
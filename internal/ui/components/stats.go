@@ -2,41 +2,27 @@ package components
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
-	"github.com/charmbracelet/lipgloss"
 	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/usage"
 )
 
-var (
-	statsPanelStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Padding(1, 2).
-			MarginTop(1)
-
-	statsTitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("220")).
-			Bold(true).
-			Underline(true)
-
-	statsLabelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			Width(20)
-
-	statsValueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86")).
-			Bold(true)
-
-	statsHelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			Italic(true)
+const (
+	// StatsPanelWidth is the width of the stats panel, border included
+	StatsPanelWidth = 56
+	// sparklineLength is the number of most recent turns drawn in sparklines
+	sparklineLength = 24
 )
 
 // StatsComponent displays request statistics
 type StatsComponent struct {
 	visible bool
+	panel   bool
 	stats   *llm.RequestStats
+	totals  *usage.Totals
+	history []*llm.RequestStats
 }
 
 // NewStatsComponent creates a new stats component
@@ -57,6 +43,39 @@ func (s *StatsComponent) SetStats(stats *llm.RequestStats) {
 	s.stats = stats
 }
 
+// SetTotals updates the session-wide totals
+func (s *StatsComponent) SetTotals(totals *usage.Totals) {
+	s.totals = totals
+}
+
+// SetHistory updates the requests of the session, drawn as sparklines
+func (s *StatsComponent) SetHistory(stats []*llm.RequestStats) {
+	history := append([]*llm.RequestStats(nil), stats...)
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].StartTime.Before(history[j].StartTime)
+	})
+	s.history = history
+}
+
+// TogglePanel shows or hides the detailed stats panel. Showing it also shows
+// stats hidden with Toggle.
+func (s *StatsComponent) TogglePanel() {
+	s.panel = !s.panel
+	if s.panel {
+		s.visible = true
+	}
+}
+
+// IsPanelVisible returns whether the detailed stats panel is shown
+func (s *StatsComponent) IsPanelVisible() bool {
+	return s.visible && s.panel
+}
+
+// Totals returns the session-wide totals
+func (s *StatsComponent) Totals() *usage.Totals {
+	return s.totals
+}
+
 // IsVisible returns whether stats are visible
 func (s *StatsComponent) IsVisible() bool {
 	return s.visible
@@ -69,7 +88,7 @@ func (s *StatsComponent) View() string {
 	}
 
 	if s.stats == nil {
-		return statsPanelStyle.Render(
+		return statsPanelStyle.Width(StatsPanelWidth - 2).Render(
 			statsTitleStyle.Render("Stats") + "\n\n" +
 				statsHelpStyle.Render("No request data yet"),
 		)
@@ -129,7 +148,67 @@ func (s *StatsComponent) View() string {
 		content.WriteString(s.renderStat("Estimate", fmt.Sprintf("$%.6f", s.stats.CostEstimate)))
 	}
 
-	return statsPanelStyle.Render(content.String())
+	// Session totals
+	if s.totals != nil && s.totals.Requests > 1 {
+		content.WriteString("\n")
+		content.WriteString(statsTitleStyle.Render("Session"))
+		content.WriteString("\n")
+		content.WriteString(s.renderStat("Requests", fmt.Sprintf("%d", s.totals.Requests)))
+		content.WriteString(s.renderStat("Input Tokens", fmt.Sprintf("%d", s.totals.InputTokens)))
+		content.WriteString(s.renderStat("Output Tokens", fmt.Sprintf("%d", s.totals.OutputTokens)))
+		if s.totals.Cost > 0 {
+			content.WriteString(s.renderStat("Total Cost", fmt.Sprintf("$%.6f", s.totals.Cost)))
+		}
+		if s.totals.AvgTTFT > 0 {
+			content.WriteString(s.renderStat("TTFT avg/p50/p95", fmt.Sprintf("%.2fs / %.2fs / %.2fs",
+				s.totals.AvgTTFT.Seconds(), s.totals.P50TTFT.Seconds(), s.totals.P95TTFT.Seconds())))
+		}
+		if s.totals.AvgSpeed > 0 {
+			content.WriteString(s.renderStat("Speed avg/p50/p95", fmt.Sprintf("%.1f / %.1f / %.1f tok/s",
+				s.totals.AvgSpeed, s.totals.P50Speed, s.totals.P95Speed)))
+		}
+	}
+
+	if len(s.history) > 1 && s.totals != nil {
+		content.WriteString("\n")
+		content.WriteString(s.renderHistory())
+	}
+
+	return statsPanelStyle.Width(StatsPanelWidth - 2).Render(content.String())
+}
+
+// renderHistory renders sparklines of TTFT, speed and the running token total
+// over the turns of the session, oldest first
+func (s *StatsComponent) renderHistory() string {
+	var ttfts, speeds, tokens []float64
+	total := 0
+	for _, st := range s.history {
+		if st.TimeToFirstToken > 0 {
+			ttfts = append(ttfts, st.TimeToFirstToken.Seconds())
+		}
+		if speed := usage.Speed(st); speed > 0 {
+			speeds = append(speeds, speed)
+		}
+		total += st.InputTokens + st.OutputTokens
+		tokens = append(tokens, float64(total))
+	}
+
+	var b strings.Builder
+	b.WriteString(statsTitleStyle.Render("History"))
+	b.WriteString("\n")
+	if len(ttfts) > 0 {
+		b.WriteString(s.renderStat("TTFT", Sparkline(ttfts, sparklineLength)))
+		b.WriteString(s.renderStat("  min/avg/max", fmt.Sprintf("%.2fs / %.2fs / %.2fs",
+			s.totals.MinTTFT.Seconds(), s.totals.AvgTTFT.Seconds(), s.totals.MaxTTFT.Seconds())))
+	}
+	if len(speeds) > 0 {
+		b.WriteString(s.renderStat("Speed", Sparkline(speeds, sparklineLength)))
+		b.WriteString(s.renderStat("  min/avg/max", fmt.Sprintf("%.1f / %.1f / %.1f tok/s",
+			s.totals.MinSpeed, s.totals.AvgSpeed, s.totals.MaxSpeed)))
+	}
+	b.WriteString(s.renderStat("Tokens", Sparkline(tokens, sparklineLength)))
+	b.WriteString(s.renderStat("  running total", fmt.Sprintf("%d", total)))
+	return b.String()
 }
 
 // renderStat renders a single stat line
@@ -0,0 +1,103 @@
+package components
+
+import (
+	"github.com/LETHEVIET/chat-tui/internal/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Component styles, set by ApplyPalette
+var (
+	helpStyle lipgloss.Style
+
+	userMessageStyle      lipgloss.Style
+	assistantMessageStyle lipgloss.Style
+	systemMessageStyle    lipgloss.Style
+	typingStyle           lipgloss.Style
+
+	statsPanelStyle lipgloss.Style
+	statsTitleStyle lipgloss.Style
+	statsLabelStyle lipgloss.Style
+	statsValueStyle lipgloss.Style
+	statsHelpStyle  lipgloss.Style
+
+	pickerTitleStyle    lipgloss.Style
+	pickerItemStyle     lipgloss.Style
+	pickerSelectedStyle lipgloss.Style
+	pickerDetailStyle   lipgloss.Style
+
+	codeLabelStyle  lipgloss.Style
+	codeGutterStyle lipgloss.Style
+)
+
+func init() {
+	ApplyPalette(theme.Default().Palette)
+}
+
+// ApplyPalette restyles the components with the colors of a theme
+func ApplyPalette(p theme.Palette) {
+	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(p.Muted)).Italic(true)
+
+	// Messages
+	userMessageStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Primary))
+
+	assistantMessageStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Secondary))
+
+	systemMessageStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Muted)).
+		Italic(true)
+
+	typingStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Muted)).
+		Italic(true)
+
+	// Stats
+	statsPanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color(p.Muted)).
+		Padding(1, 2).
+		MarginTop(1)
+
+	statsTitleStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Accent)).
+		Bold(true).
+		Underline(true)
+
+	statsLabelStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Muted)).
+		Width(20)
+
+	statsValueStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Primary)).
+		Bold(true)
+
+	statsHelpStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Muted)).
+		Italic(true)
+
+	// Picker
+	pickerTitleStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Accent)).
+		Bold(true)
+
+	pickerItemStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Text))
+
+	pickerSelectedStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Success)).
+		Bold(true)
+
+	pickerDetailStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Muted)).
+		Italic(true)
+
+	// Code blocks
+	codeLabelStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Text)).
+		Background(lipgloss.Color(p.CodeBackground)).
+		Padding(0, 1)
+
+	codeGutterStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(p.Muted))
+}
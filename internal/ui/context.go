@@ -0,0 +1,213 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/contextwin"
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gaugeWidth is the number of cells of the context usage bar
+const gaugeWidth = 10
+
+// summaryRequest is a pending summarization of older turns
+type summaryRequest struct {
+	nodeID int
+	stream bool
+}
+
+// summaryMsg carries the result of a summary request
+type summaryMsg struct {
+	request *summaryRequest
+	summary string
+	stats   *llm.RequestStats
+	err     error
+}
+
+// contextWindow returns the request context for the active path. It is built
+// once per change of the path, not on every frame.
+func (m *ChatModel) contextWindow() *contextwin.Window {
+	if m.window == nil {
+		m.window = contextwin.Build(m.messages, m.config.Context.Policy, m.config.Context.MaxTokens)
+	}
+	return m.window
+}
+
+// contextMessages returns the messages sent with the next request
+func (m *ChatModel) contextMessages() []llm.Message {
+	return m.contextWindow().Messages
+}
+
+// needsSummary reports whether the summarize policy should compact the context
+// before the next request
+func (m *ChatModel) needsSummary() bool {
+	if m.config.Context.Policy != contextwin.PolicySummarize || m.config.Context.MaxTokens <= 0 {
+		return false
+	}
+	for _, status := range m.contextWindow().Status {
+		if status == contextwin.Dropped {
+			return true
+		}
+	}
+	return false
+}
+
+// summarize asks the model to summarize older turns of the active path.
+// With stream set, the response to the latest message is streamed afterwards.
+func (m *ChatModel) summarize(stream bool) (tea.Cmd, error) {
+	start, end, ok := contextwin.SummaryRange(m.messages, m.config.Context.KeepRecent)
+	if !ok {
+		return nil, fmt.Errorf("nothing to summarize (the last %d messages are always kept)", m.config.Context.KeepRecent)
+	}
+
+	request := &summaryRequest{nodeID: m.path[end], stream: stream}
+	m.pendingSummary = request
+	m.streaming = true
+	m.streamContent = ""
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelStream = cancel
+	client := m.client
+	messages := contextwin.SummaryRequest(m.messages, start, end)
+
+	return func() tea.Msg {
+		summary, stats, err := client.Chat(ctx, messages)
+		if err != nil {
+			err = fmt.Errorf("failed to summarize context: %w", err)
+		}
+		return summaryMsg{request: request, summary: strings.TrimSpace(summary), stats: stats, err: err}
+	}, nil
+}
+
+// applySummary stores a finished summary on the first message it does not cover
+func (m *ChatModel) applySummary(msg summaryMsg) tea.Cmd {
+	if msg.request != m.pendingSummary {
+		// Result of a cancelled request
+		return nil
+	}
+	m.pendingSummary = nil
+	m.cancelStream = nil
+	if msg.err == nil {
+		m.logRequest(msg.stats)
+	}
+
+	node := m.tree.Node(msg.request.nodeID)
+	switch {
+	case msg.err != nil:
+		// The sliding window still keeps the request within budget
+		m.err = msg.err
+	case node == nil || msg.summary == "":
+		m.err = fmt.Errorf("failed to summarize context: empty summary")
+	default:
+		m.checkpoint("summarize")
+		node.Message.Summary = msg.summary
+		m.syncMessages()
+		m.autosave()
+	}
+
+	if msg.request.stream {
+		return m.streamResponse()
+	}
+	m.streaming = false
+	return nil
+}
+
+// togglePin pins or unpins message index of the active path
+func (m *ChatModel) togglePin(index int) bool {
+	m.checkpoint("pin")
+	node := m.tree.Node(m.path[index])
+	node.Message.Pinned = !node.Message.Pinned
+	m.syncMessages()
+	m.autosave()
+	return node.Message.Pinned
+}
+
+// contextReport describes the current context usage for /context
+func (m *ChatModel) contextReport() string {
+	w := m.contextWindow()
+	counts := map[contextwin.Status]int{}
+	pinned := 0
+	for i, status := range w.Status {
+		counts[status]++
+		if m.messages[i].Pinned {
+			pinned++
+		}
+	}
+
+	budget := "unlimited"
+	if w.Budget > 0 {
+		budget = fmt.Sprintf("%d tokens", w.Budget)
+	}
+
+	return fmt.Sprintf("**Context policy:** %s\n\n- Budget: %s\n- Estimated usage: %d tokens in %d messages\n- Pinned: %d • Dropped: %d • Summarized: %d",
+		m.config.Context.Policy, budget, w.Tokens, len(w.Messages), pinned, counts[contextwin.Dropped], counts[contextwin.Summarized])
+}
+
+// renderReport renders the command output overlay. Reports are not part of
+// the conversation, so they are never saved or sent to the model.
+func (m *ChatModel) renderReport() string {
+	if m.report == "" {
+		return ""
+	}
+	rendered := strings.TrimRight(m.messageComp.RenderMessage("report", m.report), "\n")
+	return rendered + "\n" + HelpStyle.Render("Press any key to close") + "\n"
+}
+
+// renderContextMarker renders how message i is represented in the request context
+func renderContextMarker(w *contextwin.Window, i int, pinned bool, summary string) string {
+	var markers []string
+	if summary != "" {
+		markers = append(markers, "≡ earlier messages replaced by summary: "+session.Truncate(summary, 60))
+	}
+	if pinned {
+		markers = append(markers, "⚑ pinned")
+	}
+	switch w.Status[i] {
+	case contextwin.Dropped:
+		markers = append(markers, "✂ outside the context window")
+	case contextwin.Summarized:
+		markers = append(markers, "≡ summarized")
+	}
+	if len(markers) == 0 {
+		return ""
+	}
+	return HelpStyle.Render(strings.Join(markers, " • "))
+}
+
+// renderContextGauge renders the context usage shown in the status bar
+func renderContextGauge(w *contextwin.Window) string {
+	if w.Budget <= 0 {
+		return HelpStyle.Render("ctx " + formatTokens(w.Tokens))
+	}
+
+	ratio := float64(w.Tokens) / float64(w.Budget)
+	filled := int(ratio*gaugeWidth + 0.5)
+	if filled > gaugeWidth {
+		filled = gaugeWidth
+	}
+
+	color := successColor
+	switch {
+	case ratio >= 0.9:
+		color = errorColor
+	case ratio >= 0.7:
+		color = accentColor
+	}
+
+	bar := lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("▰", filled)) +
+		HelpStyle.Render(strings.Repeat("▱", gaugeWidth-filled))
+	return HelpStyle.Render(fmt.Sprintf("ctx %s/%s ", formatTokens(w.Tokens), formatTokens(w.Budget))) + bar
+}
+
+// formatTokens formats a token count compactly (e.g. 1.2k)
+func formatTokens(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
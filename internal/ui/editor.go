@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorFinishedMsg is sent when the external editor exits
+type editorFinishedMsg struct {
+	nodeID  int
+	content string
+	run     bool
+	err     error
+}
+
+// editorCommand returns the user's editor from $VISUAL or $EDITOR
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// openInEditor writes content to a temp file with the given extension, suspends
+// the TUI while the editor runs and passes the edited text to done
+func openInEditor(content, ext string, done func(edited string, err error) tea.Msg) tea.Cmd {
+	f, err := os.CreateTemp("", "chat-tui-*"+ext)
+	if err != nil {
+		return func() tea.Msg { return done("", fmt.Errorf("failed to create temp file: %w", err)) }
+	}
+	path := f.Name()
+	_, err = f.WriteString(content)
+	f.Close()
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return done("", fmt.Errorf("failed to write temp file: %w", err)) }
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return done("", fmt.Errorf("editor failed: %w", err))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return done("", fmt.Errorf("failed to read edited file: %w", err))
+		}
+		return done(string(data), nil)
+	})
+}
+
+// resolveMessageIndex maps a 1-based message number over the visible
+// (non-system) messages, or a negative number counting from the end, to a path index
+func (m *ChatModel) resolveMessageIndex(n int) (int, error) {
+	var visible []int
+	for i, msg := range m.messages {
+		if msg.Role != "system" {
+			visible = append(visible, i)
+		}
+	}
+
+	switch {
+	case n > 0 && n <= len(visible):
+		return visible[n-1], nil
+	case n < 0 && -n <= len(visible):
+		return visible[len(visible)+n], nil
+	default:
+		return 0, fmt.Errorf("message %d not found (%d messages)", n, len(visible))
+	}
+}
+
+// editMessage handles /edit [n] [--no-run]
+func (m *ChatModel) editMessage(args []string) (tea.Cmd, error) {
+	index := m.lastUserIndex()
+	run := true
+
+	for _, arg := range args {
+		if arg == "--no-run" {
+			run = false
+			continue
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("usage: /edit [n] [--no-run]")
+		}
+		if index, err = m.resolveMessageIndex(n); err != nil {
+			return nil, err
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("no message to edit")
+	}
+
+	return m.editAt(index, run), nil
+}
+
+// editAt opens message index of the active path in $EDITOR
+func (m *ChatModel) editAt(index int, run bool) tea.Cmd {
+	nodeID := m.path[index]
+	msg := m.messages[index]
+	return openInEditor(msg.Content, ".md", func(edited string, err error) tea.Msg {
+		return editorFinishedMsg{nodeID: nodeID, content: edited, run: run && msg.Role == "user", err: err}
+	})
+}
+
+// applyEdit stores an edited message as a new sibling branch, keeping the
+// original, and re-runs the turn for edited user messages
+func (m *ChatModel) applyEdit(msg editorFinishedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.err = msg.err
+		return nil
+	}
+
+	node := m.tree.Node(msg.nodeID)
+	if node == nil {
+		m.err = fmt.Errorf("edited message no longer exists")
+		return nil
+	}
+
+	content := strings.TrimRight(msg.content, "\n")
+	if strings.TrimSpace(content) == "" {
+		m.err = fmt.Errorf("edit cancelled: message is empty")
+		return nil
+	}
+	if content == node.Message.Content {
+		m.err = nil
+		return nil
+	}
+
+	edited := session.NewMessage(node.Message.Role, content)
+	m.tree.AddSibling(msg.nodeID, edited)
+	m.branchCursor = -1
+	m.syncMessages()
+	m.err = nil
+
+	if !msg.run || m.streaming {
+		m.autosave()
+		return nil
+	}
+
+	m.streaming = true
+	m.streamContent = ""
+	m.focusMessage = -1
+	return m.streamResponse()
+}
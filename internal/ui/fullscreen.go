@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/contextwin"
+	"github.com/LETHEVIET/chat-tui/internal/keymap"
+	"github.com/LETHEVIET/chat-tui/internal/theme"
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// scrollStep is the number of lines a wheel notch or Ctrl+↑/↓ scrolls
+const scrollStep = 3
+
+// historyKey identifies what the banner and finished messages of the
+// full-screen transcript were rendered with. The context window is rebuilt
+// whenever the path or one of its messages changes, so it stands for them.
+type historyKey struct {
+	window         *contextwin.Window
+	theme          *theme.Theme
+	keys           *keymap.KeyMap
+	width          int
+	selected       int
+	focus          int
+	branchPoint    int
+	model, baseURL string
+	title          string
+}
+
+// historyCache holds the rendered banner and finished messages, so that
+// frames while a reply streams only render the reply
+type historyCache struct {
+	key  historyKey
+	text string
+}
+
+// Fullscreen reports whether the chat runs on the alternate screen with a
+// scrollable transcript
+func (m *ChatModel) Fullscreen() bool {
+	return m.fullscreen
+}
+
+// updateScroll scrolls the transcript in full-screen mode and reports whether
+// the key was a scroll key. Scrolling works while a reply is streaming.
+func (m *ChatModel) updateScroll(msg tea.KeyMsg) bool {
+	k := m.keys
+	if !m.fullscreen || !key.Matches(msg, k.PageUp, k.PageDown, k.ScrollUp, k.ScrollDown, k.ScrollTop, k.ScrollBottom) {
+		return false
+	}
+
+	m.loadTranscript()
+	switch {
+	case key.Matches(msg, m.keys.PageUp):
+		m.viewport.PageUp()
+	case key.Matches(msg, m.keys.PageDown):
+		m.viewport.PageDown()
+	case key.Matches(msg, m.keys.ScrollUp):
+		m.viewport.ScrollUp(scrollStep)
+	case key.Matches(msg, m.keys.ScrollDown):
+		m.viewport.ScrollDown(scrollStep)
+	case key.Matches(msg, m.keys.ScrollTop):
+		m.viewport.GotoTop()
+	case key.Matches(msg, m.keys.ScrollBottom):
+		m.viewport.GotoBottom()
+	}
+
+	// Scrolling up pauses auto-follow; reaching the bottom resumes it
+	m.follow = m.viewport.AtBottom()
+	return true
+}
+
+// updateMouse scrolls the transcript with the mouse wheel
+func (m *ChatModel) updateMouse(msg tea.MouseMsg) {
+	if !m.fullscreen || msg.Action != tea.MouseActionPress ||
+		(msg.Button != tea.MouseButtonWheelUp && msg.Button != tea.MouseButtonWheelDown) {
+		return
+	}
+
+	m.loadTranscript()
+	if msg.Button == tea.MouseButtonWheelUp {
+		m.viewport.ScrollUp(scrollStep)
+	} else {
+		m.viewport.ScrollDown(scrollStep)
+	}
+	m.follow = m.viewport.AtBottom()
+}
+
+// renderFullscreen fits the transcript into the viewport above the footer
+func (m *ChatModel) renderFullscreen(window *contextwin.Window, footer string) string {
+	m.viewport.Width = m.width
+	if m.sideStatsPane() {
+		m.viewport.Width -= components.StatsPanelWidth
+	}
+	m.viewport.Height = max(1, m.height-lipgloss.Height(footer))
+	switch {
+	case m.scrollToFocus:
+		m.scrollToFocus = false
+		m.follow = false
+		m.setViewportContent(m.renderHistory(window) + m.renderPending())
+		m.viewport.SetYOffset(m.focusOffset())
+	case m.follow:
+		// Only the bottom is visible, so the viewport gets just those lines
+		m.setViewportContent(lastLines(m.renderHistory(window), m.renderPending(), m.viewport.Height))
+		m.viewport.GotoBottom()
+	default:
+		m.setViewportContent(m.renderHistory(window) + m.renderPending())
+	}
+	view := m.viewport.View()
+	if m.sideStatsPane() {
+		view = m.joinStatsPane(view)
+	}
+	return view + "\n" + footer
+}
+
+// renderHistory returns the banner and the finished messages, rendering them
+// again only when something they show has changed
+func (m *ChatModel) renderHistory(window *contextwin.Window) string {
+	selected := -1
+	if m.selection != nil {
+		selected = m.selection.index
+	}
+	key := historyKey{
+		window:      window,
+		theme:       m.theme,
+		keys:        m.keys,
+		width:       m.contentWidth(),
+		selected:    selected,
+		focus:       m.focusMessage,
+		branchPoint: m.activeBranchPoint(),
+		model:       m.config.Model,
+		baseURL:     m.config.BaseURL,
+		title:       m.title,
+	}
+	if m.history.text == "" || m.history.key != key {
+		m.history = historyCache{key: key, text: m.renderBanner() + m.renderMessages(window, 0)}
+	}
+	return m.history.text
+}
+
+// setViewportContent hands content to the viewport if it changed, as
+// SetContent measures every line
+func (m *ChatModel) setViewportContent(content string) {
+	if content != m.viewportContent {
+		m.viewport.SetContent(content)
+		m.viewportContent = content
+	}
+}
+
+// loadTranscript gives the viewport the whole transcript before scrolling
+// away from the bottom, which is all it holds while following
+func (m *ChatModel) loadTranscript() {
+	if !m.follow {
+		return
+	}
+	m.setViewportContent(m.renderHistory(m.contextWindow()) + m.renderPending())
+	m.viewport.GotoBottom()
+}
+
+// lastLines returns the last n lines of head+tail without concatenating them
+func lastLines(head, tail string, n int) string {
+	// The last n lines start after the n-th newline from the end
+	end := len(tail)
+	for ; n > 0; n-- {
+		i := strings.LastIndexByte(tail[:end], '\n')
+		if i < 0 {
+			break
+		}
+		end = i
+	}
+	if n == 0 {
+		return tail[end+1:]
+	}
+
+	end = len(head)
+	for ; n > 0; n-- {
+		i := strings.LastIndexByte(head[:end], '\n')
+		if i < 0 {
+			return head + tail
+		}
+		end = i
+	}
+	return head[end+1:] + tail
+}
+
+// focusOffset returns the transcript line of the focused message
+func (m *ChatModel) focusOffset() int {
+	window := m.contextWindow()
+	lines := strings.Count(m.renderBanner(), "\n")
+	for i := 0; i < m.focusMessage && i < len(m.messages); i++ {
+		lines += strings.Count(m.renderMessage(window, i), "\n")
+	}
+	return lines
+}
+
+// renderScrollIndicator shows the scroll position when auto-follow is paused
+func (m *ChatModel) renderScrollIndicator() string {
+	if !m.fullscreen || m.follow {
+		return ""
+	}
+	return HelpStyle.Render(fmt.Sprintf("↑ %d%% • %s to follow", int(m.viewport.ScrollPercent()*100), m.keys.ScrollBottom.Help().Key))
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keysColumnGap is the space between groups in the key bindings overlay
+const keysColumnGap = 4
+
+// shortHelp renders enabled bindings as "key action • key action"
+func shortHelp(bindings ...key.Binding) string {
+	var parts []string
+	for _, b := range bindings {
+		if b.Enabled() {
+			parts = append(parts, b.Help().Key+" "+b.Help().Desc)
+		}
+	}
+	return strings.Join(parts, " • ")
+}
+
+// renderKeys renders the key bindings overlay: one column per group of
+// actions, wrapped into rows that fit the terminal
+func (m *ChatModel) renderKeys() string {
+	var columns []string
+	for _, group := range m.keys.Groups() {
+		if len(group.Actions) == 0 {
+			continue
+		}
+		width := 0
+		for _, a := range group.Actions {
+			width = max(width, lipgloss.Width(a.Binding.Help().Key))
+		}
+
+		var column strings.Builder
+		column.WriteString(CommandStyle.Underline(true).Render(group.Title))
+		for _, a := range group.Actions {
+			help := a.Binding.Help()
+			column.WriteString("\n")
+			column.WriteString(CommandStyle.Render(help.Key + strings.Repeat(" ", width-lipgloss.Width(help.Key))))
+			column.WriteString(HelpStyle.Render(help.Desc + " (" + a.Name + ")"))
+		}
+		columns = append(columns, lipgloss.NewStyle().MarginRight(keysColumnGap).Render(column.String()))
+	}
+
+	var rows []string
+	var row []string
+	rowWidth := 0
+	for _, column := range columns {
+		if len(row) > 0 && rowWidth+lipgloss.Width(column) > m.width {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+			row, rowWidth = nil, 0
+		}
+		row = append(row, column)
+		rowWidth += lipgloss.Width(column)
+	}
+	if len(row) > 0 {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
+	}
+
+	return strings.Join(rows, "\n\n") + "\n" + HelpStyle.Render("Press any key to close • rebind keys under keys: in .chat-tui.yaml") + "\n"
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// liveInterval is how often live stats refresh while a reply streams
+	liveInterval = 250 * time.Millisecond
+	// liveWindow is the span over which the live speed is averaged
+	liveWindow = 3 * time.Second
+)
+
+// liveStats measures a streaming reply as its chunks reach the UI. It lives on
+// the UI goroutine, so it never reads the request stats the stream goroutine
+// is still writing; those are shown once the reply is done.
+type liveStats struct {
+	start      time.Time
+	firstToken time.Duration
+	tokens     int
+	arrivals   []time.Time // chunk arrival times within liveWindow
+}
+
+// liveTickMsg refreshes the live stats of the stream they belong to
+type liveTickMsg struct {
+	live *liveStats
+}
+
+// startLiveStats starts measuring a new stream and returns the ticker command
+func (m *ChatModel) startLiveStats() tea.Cmd {
+	m.live = &liveStats{start: time.Now()}
+	return liveTick(m.live)
+}
+
+// liveTick schedules the next refresh of live
+func liveTick(live *liveStats) tea.Cmd {
+	return tea.Tick(liveInterval, func(time.Time) tea.Msg {
+		return liveTickMsg{live: live}
+	})
+}
+
+// updateLiveTick keeps ticking while the stream of msg is still running
+func (m *ChatModel) updateLiveTick(msg liveTickMsg) tea.Cmd {
+	if msg.live != m.live || !m.streaming {
+		return nil
+	}
+	return liveTick(m.live)
+}
+
+// observe records a content chunk received at now. Like the client, it counts
+// one token per chunk.
+func (l *liveStats) observe(now time.Time) {
+	if l.tokens == 0 {
+		l.firstToken = now.Sub(l.start)
+	}
+	l.tokens++
+	l.arrivals = append(l.arrivals, now)
+	l.trim(now)
+}
+
+// trim drops arrivals older than liveWindow
+func (l *liveStats) trim(now time.Time) {
+	cut := 0
+	for cut < len(l.arrivals) && now.Sub(l.arrivals[cut]) > liveWindow {
+		cut++
+	}
+	l.arrivals = l.arrivals[cut:]
+}
+
+// speed returns the tokens per second over the last liveWindow, or since the
+// first token if that was more recent
+func (l *liveStats) speed(now time.Time) float64 {
+	recent := 0
+	for _, at := range l.arrivals {
+		if now.Sub(at) <= liveWindow {
+			recent++
+		}
+	}
+	span := min(liveWindow, max(liveInterval, now.Sub(l.start)-l.firstToken))
+	if recent == 0 {
+		return 0
+	}
+	return float64(recent) / span.Seconds()
+}
+
+// render renders the live stats for the status line, like the compact stats
+func (l *liveStats) render(now time.Time) string {
+	parts := []string{fmt.Sprintf("● %.1fs", now.Sub(l.start).Seconds())}
+	if l.tokens == 0 {
+		parts = append(parts, "waiting for first token")
+	} else {
+		parts = append(parts,
+			fmt.Sprintf("TTFT: %.2fs", l.firstToken.Seconds()),
+			fmt.Sprintf("%d tok", l.tokens),
+			fmt.Sprintf("%.1f tok/s", l.speed(now)))
+	}
+	return TypingStyle.Render("[" + strings.Join(parts, " • ") + "]")
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxRetryAlternatives caps the number of concurrent /retry generations
+const maxRetryAlternatives = 8
+
+// retryBatch tracks the alternatives generated concurrently by /retry n
+type retryBatch struct {
+	parent  int
+	total   int
+	results []retryResultMsg
+	cancel  context.CancelFunc
+}
+
+// retryResultMsg carries one fully generated alternative
+type retryResultMsg struct {
+	batch   *retryBatch
+	content string
+	stats   *llm.RequestStats
+	err     error
+}
+
+// lastUserIndex returns the path index of the last user message, or -1
+func (m *ChatModel) lastUserIndex() int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Role == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// retry regenerates the reply to the last user message n times. Previous
+// replies are kept as sibling alternatives.
+func (m *ChatModel) retry(n int) (tea.Cmd, error) {
+	if n < 1 || n > maxRetryAlternatives {
+		return nil, fmt.Errorf("number of alternatives must be between 1 and %d", maxRetryAlternatives)
+	}
+
+	userIndex := m.lastUserIndex()
+	if userIndex < 0 {
+		return nil, fmt.Errorf("no message to retry")
+	}
+	userID := m.path[userIndex]
+
+	// Remember where the path was so a failed retry can restore it
+	m.streamFallback = -1
+	if leaf := m.tree.Leaf(); leaf != userID {
+		m.streamFallback = leaf
+	}
+	m.tree.EndPathAt(userID)
+	m.syncMessages()
+
+	m.streaming = true
+	m.streamContent = ""
+	m.focusMessage = -1
+
+	if n == 1 {
+		return m.streamResponse(), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batch := &retryBatch{parent: userID, total: n, cancel: cancel}
+	m.retryBatch = batch
+	messages := m.contextMessages()
+	client := m.client
+
+	cmds := make([]tea.Cmd, n)
+	for i := range cmds {
+		cmds[i] = func() tea.Msg {
+			return collectAlternative(ctx, client, messages, batch)
+		}
+	}
+	return tea.Batch(cmds...), nil
+}
+
+// collectAlternative streams one complete response
+func collectAlternative(ctx context.Context, client llm.Client, messages []llm.Message, batch *retryBatch) retryResultMsg {
+	chunks, stats, err := client.ChatStream(ctx, messages)
+	if err != nil {
+		return retryResultMsg{batch: batch, err: err}
+	}
+
+	var content strings.Builder
+	for chunk := range chunks {
+		if chunk.Error != nil {
+			return retryResultMsg{batch: batch, stats: stats, err: chunk.Error}
+		}
+		content.WriteString(chunk.Content)
+	}
+	return retryResultMsg{batch: batch, content: content.String(), stats: stats}
+}
+
+// updateRetry collects /retry n results and adds them as alternatives once all arrived
+func (m *ChatModel) updateRetry(msg retryResultMsg) {
+	if m.retryBatch == nil || msg.batch != m.retryBatch {
+		// Result of a cancelled batch
+		return
+	}
+
+	batch := m.retryBatch
+	batch.results = append(batch.results, msg)
+	if len(batch.results) < batch.total {
+		return
+	}
+
+	batch.cancel()
+	m.retryBatch = nil
+	m.streaming = false
+
+	added := 0
+	var lastErr error
+	for _, result := range batch.results {
+		if result.err != nil || result.content == "" {
+			lastErr = result.err
+			continue
+		}
+		m.recordStats(result.stats)
+		reply := session.NewMessage("assistant", result.content)
+		reply.Stats = result.stats
+		m.tree.AddChild(batch.parent, reply)
+		added++
+	}
+
+	if added == 0 {
+		m.restoreStreamFallback()
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no alternatives were generated")
+		}
+		m.err = lastErr
+		return
+	}
+
+	m.streamFallback = -1
+	m.branchCursor = -1
+	m.syncMessages()
+	if lastErr != nil {
+		m.err = fmt.Errorf("%d of %d alternatives failed: %w", batch.total-added, batch.total, lastErr)
+	}
+	m.autosave()
+}
+
+// cancelRetry cancels the requests of a running /retry n batch
+func (m *ChatModel) cancelRetry() {
+	if m.retryBatch != nil {
+		m.retryBatch.cancel()
+		m.retryBatch = nil
+	}
+}
+
+// restoreStreamFallback re-activates the branch that was shown before a failed retry
+func (m *ChatModel) restoreStreamFallback() {
+	if m.streamFallback >= 0 && m.tree.Node(m.streamFallback) != nil {
+		m.tree.Activate(m.streamFallback)
+		m.syncMessages()
+	}
+	m.streamFallback = -1
+}
+
+// renderRetryProgress renders the progress of a /retry n batch
+func (m *ChatModel) renderRetryProgress() string {
+	if m.retryBatch == nil {
+		return ""
+	}
+	return TypingStyle.Render(fmt.Sprintf("generating %d alternatives (%d/%d done)...",
+		m.retryBatch.total, len(m.retryBatch.results), m.retryBatch.total))
+}
@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/contextwin"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scrollbackState tracks what inline mode has printed to the terminal
+// scrollback. Printed messages leave the live view, so a redraw only covers
+// the streaming reply, the input and the status line.
+type scrollbackState struct {
+	started bool             // the banner has been printed
+	printed []printedMessage // path prefix already in the scrollback
+	pending bool             // a print is in flight
+}
+
+// printedMessage identifies a message of the path that was printed, with the
+// markers it was printed with
+type printedMessage struct {
+	id       int
+	stamp    time.Time
+	status   contextwin.Status
+	pinned   bool
+	summary  bool
+	branches int
+}
+
+// printedState returns what is printed for message i of the path
+func (m *ChatModel) printedState(window *contextwin.Window, i int) printedMessage {
+	_, branches := m.tree.Siblings(m.path[i])
+	msg := m.messages[i]
+	return printedMessage{
+		id:       m.path[i],
+		stamp:    msg.Timestamp,
+		status:   window.Status[i],
+		pinned:   msg.Pinned,
+		summary:  msg.Summary != "",
+		branches: branches,
+	}
+}
+
+// scrollbackMsg reports that a print to the scrollback has completed
+type scrollbackMsg struct{}
+
+// flushScrollback prints finished messages that are not in the scrollback yet.
+// Prints are chained one at a time so they land in order.
+func (m *ChatModel) flushScrollback() tea.Cmd {
+	sb := &m.scrollback
+	if m.fullscreen || sb.pending {
+		return nil
+	}
+
+	var out strings.Builder
+	if !sb.started {
+		out.WriteString(m.renderBanner())
+		sb.started = true
+	}
+
+	// Anything after the first message that no longer matches was undone,
+	// deleted or switched away from; it stays above and the path is reprinted
+	keep := 0
+	for keep < len(sb.printed) && keep < len(m.path) &&
+		sb.printed[keep].id == m.path[keep] && sb.printed[keep].stamp.Equal(m.messages[keep].Timestamp) {
+		keep++
+	}
+	if keep < len(sb.printed) {
+		sb.printed = sb.printed[:keep]
+		if n := m.visibleBefore(keep); n == 0 {
+			out.WriteString(m.renderBanner())
+		} else {
+			out.WriteString(HelpStyle.Render(fmt.Sprintf("── conversation changed, continuing after message %d ──", n)))
+			out.WriteString("\n\n")
+		}
+	}
+
+	window := m.contextWindow()
+	out.WriteString(m.markerChanges(window))
+	for i := keep; i < len(m.messages); i++ {
+		out.WriteString(m.renderMessage(window, i))
+		sb.printed = append(sb.printed, m.printedState(window, i))
+	}
+
+	text := strings.TrimRight(out.String(), "\n")
+	if text == "" {
+		return nil
+	}
+	sb.pending = true
+	return tea.Sequence(tea.Println(text), func() tea.Msg { return scrollbackMsg{} })
+}
+
+// markerChanges describes the markers of printed messages that changed since
+// they were printed, such as messages leaving the context window, and records
+// them as printed
+func (m *ChatModel) markerChanges(window *contextwin.Window) string {
+	var order []string
+	changed := map[string][]int{}
+	note := func(change string, n int) {
+		if _, ok := changed[change]; !ok {
+			order = append(order, change)
+		}
+		changed[change] = append(changed[change], n)
+	}
+
+	n := 0
+	for i, was := range m.scrollback.printed {
+		if m.messages[i].Role == "system" {
+			continue
+		}
+		n++
+		now := m.printedState(window, i)
+		if now.status != was.status {
+			switch now.status {
+			case contextwin.Dropped:
+				note("✂ outside the context window", n)
+			case contextwin.Summarized:
+				note("≡ summarized", n)
+			default:
+				note("back in the context window", n)
+			}
+		}
+		if now.summary && !was.summary {
+			note("≡ earlier messages replaced by its summary", n)
+		}
+		if now.pinned != was.pinned {
+			if now.pinned {
+				note("⚑ pinned", n)
+			} else {
+				note("unpinned", n)
+			}
+		}
+		if now.branches > was.branches {
+			note(fmt.Sprintf("⎇ new branch (%s/%s to switch)", m.keys.BranchPrev.Help().Key, m.keys.BranchNext.Help().Key), n)
+		}
+		m.scrollback.printed[i] = now
+	}
+
+	var b strings.Builder
+	for _, change := range order {
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("%s: %s", describeMessages(changed[change]), change)))
+		b.WriteString("\n")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// describeMessages names message numbers, joining runs, e.g. "messages 1–4, 7"
+func describeMessages(numbers []int) string {
+	if len(numbers) == 1 {
+		return fmt.Sprintf("message %d", numbers[0])
+	}
+	var runs []string
+	for start := 0; start < len(numbers); {
+		end := start
+		for end+1 < len(numbers) && numbers[end+1] == numbers[end]+1 {
+			end++
+		}
+		if end == start {
+			runs = append(runs, fmt.Sprint(numbers[start]))
+		} else {
+			runs = append(runs, fmt.Sprintf("%d–%d", numbers[start], numbers[end]))
+		}
+		start = end + 1
+	}
+	return "messages " + strings.Join(runs, ", ")
+}
+
+// visibleBefore counts the messages shown in the transcript before path index i
+func (m *ChatModel) visibleBefore(i int) int {
+	n := 0
+	for _, msg := range m.messages[:i] {
+		if msg.Role != "system" {
+			n++
+		}
+	}
+	return n
+}
+
+// unprinted returns the path index of the first message not in the scrollback
+func (m *ChatModel) unprinted() int {
+	if m.fullscreen {
+		return 0
+	}
+	return min(len(m.scrollback.printed), len(m.messages))
+}
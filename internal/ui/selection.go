@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewLength is the number of runes of a message shown in one-line previews
+const previewLength = 80
+
+// maxPreviewLines is the most lines of the selected message that inline mode
+// shows above the selection bar
+const maxPreviewLines = 12
+
+// selectionState is the message selection mode
+type selectionState struct {
+	index  int // path index of the highlighted message
+	notice string
+	detail string
+}
+
+// selectableIndices returns the path indices of messages that can be selected
+func (m *ChatModel) selectableIndices() []int {
+	var indices []int
+	for i, msg := range m.messages {
+		if msg.Role != "system" {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// startSelection enters selection mode on the last message
+func (m *ChatModel) startSelection() bool {
+	indices := m.selectableIndices()
+	if len(indices) == 0 {
+		return false
+	}
+	m.selection = &selectionState{index: indices[len(indices)-1]}
+	m.suggestions = nil
+	return true
+}
+
+// moveSelection highlights the message delta positions away, clamped to the ends
+func (m *ChatModel) moveSelection(delta int) {
+	indices := m.selectableIndices()
+	pos := 0
+	for i, index := range indices {
+		if index <= m.selection.index {
+			pos = i
+		}
+	}
+	pos = max(0, min(len(indices)-1, pos+delta))
+	m.selection.index = indices[pos]
+	m.selection.notice = ""
+	m.selection.detail = ""
+}
+
+// updateSelection handles keys in selection mode
+func (m *ChatModel) updateSelection(msg tea.KeyMsg) tea.Cmd {
+	sel := m.selection
+	msgIndex := sel.index
+	message := m.messages[msgIndex]
+
+	switch keys := m.keys; {
+	case key.Matches(msg, keys.SelectDone):
+		m.selection = nil
+	case key.Matches(msg, keys.SelectUp):
+		m.moveSelection(-1)
+	case key.Matches(msg, keys.SelectDown):
+		m.moveSelection(1)
+	case key.Matches(msg, keys.SelectFirst):
+		m.moveSelection(-len(m.messages))
+	case key.Matches(msg, keys.SelectLast):
+		m.moveSelection(len(m.messages))
+
+	case key.Matches(msg, keys.CopyText):
+		text, err := components.RenderPlain(message.Content)
+		if err != nil {
+			text = message.Content
+		}
+		sel.notice = m.copyToClipboard(text, "Message copied as text")
+	case key.Matches(msg, keys.CopyMarkdown):
+		sel.notice = m.copyToClipboard(message.Content, "Message copied as markdown")
+
+	case key.Matches(msg, keys.Delete):
+		m.checkpoint("delete")
+		m.tree.Splice(m.path[msgIndex])
+		m.branchCursor = -1
+		m.syncMessages()
+		m.autosave()
+		indices := m.selectableIndices()
+		if len(indices) == 0 {
+			m.selection = nil
+			break
+		}
+		// Keep the highlight at the same position
+		sel.index = min(msgIndex, len(m.messages)-1)
+		m.moveSelection(0)
+		sel.notice = "Message deleted"
+
+	case key.Matches(msg, keys.Edit):
+		if m.compare != nil {
+			sel.notice = "Editing is not available in compare mode"
+			break
+		}
+		m.selection = nil
+		return m.editAt(msgIndex, true)
+
+	case key.Matches(msg, keys.Pin):
+		if m.togglePin(msgIndex) {
+			sel.notice = "Message pinned"
+		} else {
+			sel.notice = "Message unpinned"
+		}
+
+	case key.Matches(msg, keys.Fork):
+		// Continue the conversation from here; later messages stay on their branch
+		m.checkpoint("fork")
+		m.tree.EndPathAt(m.path[msgIndex])
+		m.branchCursor = -1
+		m.syncMessages()
+		m.selection = nil
+		m.err = nil
+
+	case key.Matches(msg, keys.MessageStats):
+		if message.Stats == nil {
+			sel.notice = "No stats for this message"
+			break
+		}
+		stats := components.NewStatsComponent()
+		stats.SetStats(message.Stats)
+		sel.detail = stats.View()
+	}
+
+	return nil
+}
+
+// selectionHelp lists the actions of message selection mode with their keys
+func (m *ChatModel) selectionHelp() string {
+	k := m.keys
+	return shortHelp(k.SelectUp, k.SelectDown, k.CopyText, k.CopyMarkdown, k.Delete, k.Edit, k.Pin, k.Fork, k.MessageStats, k.SelectDone)
+}
+
+// copyToClipboard copies text and returns the notice to show
+func (m *ChatModel) copyToClipboard(text, notice string) string {
+	if err := clipboard.WriteAll(text); err != nil {
+		return fmt.Sprintf("Failed to copy: %v", err)
+	}
+	return notice
+}
+
+// renderSelectionBar renders the selection mode help, notice and stats detail
+func (m *ChatModel) renderSelectionBar() string {
+	var b strings.Builder
+	if !m.fullscreen {
+		// Inline mode: the transcript is in the scrollback, so preview the selection here
+		b.WriteString(m.renderSelectionPreview())
+	}
+	if m.selection.detail != "" {
+		b.WriteString(m.selection.detail)
+		b.WriteString("\n")
+	}
+	if m.selection.notice != "" {
+		b.WriteString(SuccessStyle.Render(m.selection.notice))
+		b.WriteString("\n")
+	}
+	b.WriteString(CommandStyle.Render("SELECT"))
+	b.WriteString(HelpStyle.Render(m.selectionHelp()))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// renderSelectionPreview renders the selected message, highlighted and cut to
+// at most maxPreviewLines lines, with its number in the transcript
+func (m *ChatModel) renderSelectionPreview() string {
+	msg := m.messages[m.selection.index]
+	rendered := strings.TrimRight(m.messageComp.RenderMessage(msg.Role, msg.Content), "\n")
+	lines := strings.Split(rendered, "\n")
+	limit := maxPreviewLines
+	if m.height > 0 {
+		limit = max(1, min(limit, m.height/3))
+	}
+	var more string
+	if len(lines) > limit {
+		more = HelpStyle.Render(fmt.Sprintf("… %d more lines", len(lines)-limit)) + "\n"
+		lines = lines[:limit]
+	}
+	return HelpStyle.Render(fmt.Sprintf("Message #%d", m.visibleBefore(m.selection.index)+1)) + "\n" +
+		SelectedMessageStyle.Render(strings.Join(lines, "\n")) + "\n" + more
+}
@@ -0,0 +1,407 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/export"
+	"github.com/LETHEVIET/chat-tui/internal/search"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	"github.com/LETHEVIET/chat-tui/internal/usage"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionPickerHeight is the number of sessions shown at once in /sessions
+const sessionPickerHeight = 10
+
+// searchResultLimit caps the number of results listed by /find
+const searchResultLimit = 50
+
+// toSession captures the current conversation and client settings
+func (m *ChatModel) toSession() *session.Session {
+	return &session.Session{
+		ID:           m.sessionID,
+		Title:        m.title,
+		Cwd:          m.cwd,
+		CreatedAt:    m.createdAt,
+		Model:        m.client.GetModel(),
+		BaseURL:      m.config.BaseURL,
+		Temperature:  m.client.GetTemperature(),
+		MaxTokens:    m.config.MaxTokens,
+		SystemPrompt: m.systemPrompt,
+		Messages:     m.tree.Messages(),
+		Tree:         m.tree.Clone(),
+		Source:       m.source,
+		Usage:        m.ledger,
+	}
+}
+
+// restoreSession replaces the conversation and client settings with a saved session
+func (m *ChatModel) restoreSession(s *session.Session) {
+	// Imported sessions continue with the configured model and endpoint
+	if s.Source == "" {
+		cfg := *m.config
+		if s.Model != "" {
+			cfg.Model = s.Model
+		}
+		if s.BaseURL != "" {
+			cfg.BaseURL = s.BaseURL
+		}
+		if s.MaxTokens > 0 {
+			cfg.MaxTokens = s.MaxTokens
+		}
+		cfg.Temperature = s.Temperature
+		m.config = &cfg
+		m.client = newClient(m.config, m.logger)
+	}
+
+	m.tree = s.Tree
+	m.ledger = s.Usage
+	if m.ledger == nil || len(m.ledger.Log) == 0 {
+		// Sessions saved before the ledger only know what the tree holds
+		m.ledger = usage.NewLedger(s.Tree.Stats())
+	}
+	m.branchCursor = -1
+	m.syncMessages()
+	m.systemPrompt = s.SystemPrompt
+	m.createdAt = s.CreatedAt
+	m.sessionID = s.ID
+	m.source = s.Source
+	m.title = s.Title
+	m.titleRequested = false
+	m.streamContent = ""
+	m.focusMessage = -1
+
+	// Show the stats of the last completed turn
+	m.stats.SetStats(nil)
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Stats != nil {
+			m.stats.SetStats(m.messages[i].Stats)
+			break
+		}
+	}
+}
+
+// newSession checkpoints the conversation and starts an empty one
+func (m *ChatModel) newSession() {
+	m.checkpoint("clear")
+	m.ledger = &usage.Ledger{}
+	m.resetConversation()
+	m.streamContent = ""
+	m.createdAt = time.Now()
+	m.sessionID = ""
+	m.source = ""
+	m.title = ""
+	m.titleRequested = false
+}
+
+// userTurns returns the number of user messages on the active path
+func (m *ChatModel) userTurns() int {
+	turns := 0
+	for _, msg := range m.messages {
+		if msg.Role == "user" {
+			turns++
+		}
+	}
+	return turns
+}
+
+// hasUserMessages reports whether the conversation has any user turn
+func (m *ChatModel) hasUserMessages() bool {
+	for _, msg := range m.messages {
+		if msg.Role == "user" {
+			return true
+		}
+	}
+	return false
+}
+
+// autosave writes the conversation to the session store after a completed turn
+func (m *ChatModel) autosave() {
+	if m.store == nil || !m.config.Sessions.Autosave || !m.hasUserMessages() {
+		return
+	}
+
+	s := m.toSession()
+	if err := m.store.Save(s); err != nil {
+		m.err = fmt.Errorf("autosave failed: %w", err)
+		return
+	}
+	m.sessionID = s.ID
+	m.createdAt = s.CreatedAt
+	m.indexSession(s)
+}
+
+// openSearchIndex returns the search index of the session store
+func (m *ChatModel) openSearchIndex() (*search.Index, error) {
+	if m.searchIndex == nil {
+		idx, err := search.Open(m.store)
+		if err != nil {
+			return nil, err
+		}
+		m.searchIndex = idx
+	}
+	return m.searchIndex, nil
+}
+
+// indexSession queues a saved session for the search index, so searches never
+// wait for the sessions saved since the last one. Only the latest save of a
+// session matters, so a newer one replaces the queued one.
+func (m *ChatModel) indexSession(s *session.Session) {
+	m.indexQueue = s
+}
+
+// indexedMsg reports an index update run by indexSaved
+type indexedMsg struct {
+	index *search.Index
+	err   error
+}
+
+// indexSaved adds the queued session to the search index in the background:
+// rewriting a large index takes seconds
+func (m *ChatModel) indexSaved() tea.Cmd {
+	if m.indexQueue == nil || m.indexing {
+		return nil
+	}
+	s, idx, store := m.indexQueue, m.searchIndex, m.store
+	m.indexQueue = nil
+	m.indexing = true
+
+	return func() tea.Msg {
+		if idx == nil {
+			var err error
+			if idx, err = search.Open(store); err != nil {
+				return indexedMsg{err: err}
+			}
+		}
+		return indexedMsg{index: idx, err: idx.AddSaved(store, s)}
+	}
+}
+
+// handleIndexed records the outcome of an index update
+func (m *ChatModel) handleIndexed(msg indexedMsg) {
+	m.indexing = false
+	if m.searchIndex == nil {
+		m.searchIndex = msg.index
+	}
+	if msg.err != nil {
+		// Searching syncs the index again, so this only costs time
+		m.logger.Slog().Warn("search index update failed", "error", msg.err.Error())
+	}
+}
+
+// ResumeSession restores a stored session by ID or ID prefix
+func (m *ChatModel) ResumeSession(id string) error {
+	if m.store == nil {
+		return fmt.Errorf("session store unavailable")
+	}
+
+	s, err := m.store.Load(id)
+	if err != nil {
+		return err
+	}
+	if m.hasUserMessages() {
+		m.checkpointSession("session switch")
+	}
+	m.restoreSession(s)
+	return nil
+}
+
+// ContinueSession restores the most recent session of the current directory
+func (m *ChatModel) ContinueSession() error {
+	if m.store == nil {
+		return fmt.Errorf("session store unavailable")
+	}
+
+	latest, err := m.store.Latest(m.cwd)
+	if err != nil {
+		return err
+	}
+	return m.ResumeSession(latest.ID)
+}
+
+// openSessionPicker shows the stored sessions for /sessions
+func (m *ChatModel) openSessionPicker() error {
+	if m.store == nil {
+		return fmt.Errorf("session store unavailable")
+	}
+
+	summaries, err := m.store.List()
+	if err != nil {
+		return err
+	}
+	if len(summaries) == 0 {
+		return fmt.Errorf("no saved sessions in %s", m.store.Dir())
+	}
+
+	items := make([]components.PickerItem, 0, len(summaries))
+	for _, sum := range summaries {
+		items = append(items, components.PickerItem{
+			Key:   sum.ID,
+			Label: session.Truncate(sum.Title, 50),
+			Detail: fmt.Sprintf("%s • %s • %d turns",
+				sum.Model, sum.UpdatedAt.Local().Format("2006-01-02 15:04"), sum.Turns),
+		})
+	}
+
+	m.openPicker("Sessions", items, sessionPickerHeight, func(item components.PickerItem) tea.Cmd {
+		if err := m.ResumeSession(item.Key); err != nil {
+			m.err = err
+		}
+		return nil
+	})
+	return nil
+}
+
+// openSearchPicker runs a full-text search and lists the matching messages
+func (m *ChatModel) openSearchPicker(query string) error {
+	if m.store == nil {
+		return fmt.Errorf("session store unavailable")
+	}
+
+	idx, err := m.openSearchIndex()
+	if err != nil {
+		return err
+	}
+	q, results, err := idx.Find(m.store, query, searchResultLimit)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no matches for %q", query)
+	}
+
+	items := make([]components.PickerItem, 0, len(results))
+	for _, r := range results {
+		snippet := search.Snippet(r.Content, q.HighlightTerms(), 60, func(s string) string {
+			return CommandStyle.Render(s)
+		})
+		items = append(items, components.PickerItem{
+			Key:   fmt.Sprintf("%s#%d", r.Session, r.Node),
+			Label: fmt.Sprintf("[%s] %s", r.Role, snippet),
+			Detail: fmt.Sprintf("%s • %s",
+				session.Truncate(r.Title, 30), r.Timestamp.Local().Format("2006-01-02")),
+		})
+	}
+
+	m.openPicker(fmt.Sprintf("Search: %s", query), items, sessionPickerHeight, func(item components.PickerItem) tea.Cmd {
+		id, node, _ := strings.Cut(item.Key, "#")
+		if err := m.ResumeSession(id); err != nil {
+			m.err = err
+			return nil
+		}
+		if n, err := strconv.Atoi(node); err == nil {
+			m.FocusNode(n)
+		}
+		return nil
+	})
+	return nil
+}
+
+// FocusNode marks a message of the conversation tree, e.g. a search match:
+// its branch becomes the active path and the transcript scrolls to it. The
+// inline transcript is in the terminal scrollback, so there the match is
+// only marked.
+func (m *ChatModel) FocusNode(id int) {
+	if m.tree.Node(id) == nil {
+		return
+	}
+	m.tree.Activate(id)
+	m.syncMessages()
+	for i, nodeID := range m.path {
+		if nodeID != id {
+			continue
+		}
+		m.focusMessage = i
+		if m.fullscreen {
+			m.scrollToFocus = true
+		} else {
+			m.notice = fmt.Sprintf("Search match: message %d, marked ▶ above", m.visibleBefore(i)+1)
+		}
+	}
+}
+
+// openPicker shows a picker overlay and calls onPick with the chosen item
+func (m *ChatModel) openPicker(title string, items []components.PickerItem, height int, onPick func(components.PickerItem) tea.Cmd) {
+	m.picker = components.NewPickerComponent(title, items, height)
+	m.onPick = onPick
+}
+
+// closePicker hides the picker overlay
+func (m *ChatModel) closePicker() {
+	m.picker = nil
+	m.onPick = nil
+}
+
+// updatePicker handles keys while a picker is open
+func (m *ChatModel) updatePicker(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.keys.PickerUp):
+		m.picker.MoveUp()
+	case key.Matches(msg, m.keys.PickerDown):
+		m.picker.MoveDown()
+	case key.Matches(msg, m.keys.PickerClose):
+		m.closePicker()
+	case key.Matches(msg, m.keys.PickerPick):
+		item, ok := m.picker.Selected()
+		onPick := m.onPick
+		m.closePicker()
+		if ok && onPick != nil {
+			return onPick(item)
+		}
+	}
+	return nil
+}
+
+// exportConversation handles /export [md|html|json|txt] [path] [--system] [--stats]
+func (m *ChatModel) exportConversation(args []string) (string, error) {
+	format := export.FormatMarkdown
+	formatSet := false
+	path := ""
+	opts := export.Options{Title: m.title}
+
+	for _, arg := range args {
+		switch {
+		case arg == "--system":
+			opts.IncludeSystem = true
+		case arg == "--stats":
+			opts.IncludeStats = true
+		case strings.HasPrefix(arg, "--"):
+			return "", fmt.Errorf("unknown export option: %s (use --system or --stats)", arg)
+		case !formatSet && path == "":
+			if f, err := export.ParseFormat(arg); err == nil {
+				format = f
+				formatSet = true
+				continue
+			}
+			path = arg
+		case path == "":
+			path = arg
+		default:
+			return "", fmt.Errorf("usage: /export [md|html|json|txt] [path] [--system] [--stats]")
+		}
+	}
+
+	if path == "" {
+		path = export.DefaultFilename(format, m.title, time.Now())
+	} else if !formatSet {
+		if f, ok := export.FormatFromPath(path); ok {
+			format = f
+		}
+	}
+
+	data, err := export.Render(format, m.messages, opts)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return path, nil
+}
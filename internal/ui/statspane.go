@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minTranscriptWidth is the narrowest transcript kept beside the stats pane;
+// narrower terminals show the pane below the transcript instead
+const minTranscriptWidth = 40
+
+// sideStatsPane reports whether the stats pane is drawn beside the transcript,
+// which needs the full-screen layout and a wide enough terminal
+func (m *ChatModel) sideStatsPane() bool {
+	return m.fullscreen && m.stats.IsPanelVisible() && m.width-components.StatsPanelWidth >= minTranscriptWidth
+}
+
+// toggleStats shows or hides stats, or only the detailed pane if panel is set
+func (m *ChatModel) toggleStats(panel bool) {
+	if panel {
+		m.stats.TogglePanel()
+	} else {
+		m.stats.Toggle()
+	}
+	// The side pane takes its width from the transcript
+	m.resizeMessages()
+}
+
+// renderStatsPane renders the stats pane below the transcript, unless it is
+// drawn beside it
+func (m *ChatModel) renderStatsPane() string {
+	if !m.stats.IsPanelVisible() || m.sideStatsPane() {
+		return ""
+	}
+	return m.stats.View() + "\n"
+}
+
+// joinStatsPane draws the stats pane to the right of the transcript view,
+// cut to its height so the footer stays in place
+func (m *ChatModel) joinStatsPane(transcript string) string {
+	lines := strings.Split(m.stats.View(), "\n")
+	if len(lines) > m.viewport.Height {
+		lines = lines[:m.viewport.Height]
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, transcript, strings.Join(lines, "\n"))
+}
@@ -4,123 +4,170 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/LETHEVIET/chat-tui/internal/theme"
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
+	// Colors of the current theme
+	primaryColor   lipgloss.Color
+	secondaryColor lipgloss.Color
+	accentColor    lipgloss.Color
+	mutedColor     lipgloss.Color
+	errorColor     lipgloss.Color
+	successColor   lipgloss.Color
+
+	// Styles of the current theme, set by ApplyTheme
+	baseStyle             lipgloss.Style
+	UserMessageStyle      lipgloss.Style
+	AssistantMessageStyle lipgloss.Style
+	SystemMessageStyle    lipgloss.Style
+	InputStyle            lipgloss.Style
+	FocusedInputStyle     lipgloss.Style
+	StatsPanelStyle       lipgloss.Style
+	StatsTitleStyle       lipgloss.Style
+	StatsLabelStyle       lipgloss.Style
+	StatsValueStyle       lipgloss.Style
+	ErrorStyle            lipgloss.Style
+	SuccessStyle          lipgloss.Style
+	TypingStyle           lipgloss.Style
+	HelpStyle             lipgloss.Style
+	CodeBlockStyle        lipgloss.Style
+	SelectedMessageStyle  lipgloss.Style
+	CommandStyle          lipgloss.Style
+	DividerStyle          lipgloss.Style
+	TitleStyle            lipgloss.Style
+)
+
+func init() {
+	ApplyTheme(theme.Default())
+}
+
+// ApplyTheme restyles the interface with the colors of t. Markdown is styled
+// separately by the message component.
+func ApplyTheme(t *theme.Theme) {
 	// Colors
-	primaryColor   = lipgloss.Color("86")  // Cyan
-	secondaryColor = lipgloss.Color("212") // Pink
-	accentColor    = lipgloss.Color("220") // Yellow
-	mutedColor     = lipgloss.Color("240") // Gray
-	errorColor     = lipgloss.Color("196") // Red
-	successColor   = lipgloss.Color("46")  // Green
+	p := t.Palette
+	primaryColor = lipgloss.Color(p.Primary)
+	secondaryColor = lipgloss.Color(p.Secondary)
+	accentColor = lipgloss.Color(p.Accent)
+	mutedColor = lipgloss.Color(p.Muted)
+	errorColor = lipgloss.Color(p.Error)
+	successColor = lipgloss.Color(p.Success)
 
 	// Base styles
 	baseStyle = lipgloss.NewStyle().
-			Padding(0, 1)
+		Padding(0, 1)
 
 	// User message style
 	UserMessageStyle = lipgloss.NewStyle().
-				Foreground(primaryColor).
-				Bold(true).
-				Padding(0, 1)
+		Foreground(primaryColor).
+		Bold(true).
+		Padding(0, 1)
 
 	// Assistant message style
 	AssistantMessageStyle = lipgloss.NewStyle().
-				Foreground(secondaryColor).
-				Bold(true).
-				Padding(0, 1)
+		Foreground(secondaryColor).
+		Bold(true).
+		Padding(0, 1)
 
 	// System message style
 	SystemMessageStyle = lipgloss.NewStyle().
-				Foreground(mutedColor).
-				Italic(true).
-				Padding(0, 1)
+		Foreground(mutedColor).
+		Italic(true).
+		Padding(0, 1)
 
 	// Input box style
 	InputStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(0, 1)
 
 	// Focused input style
 	FocusedInputStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(accentColor).
-				Padding(0, 1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accentColor).
+		Padding(0, 1)
 
 	// Stats panel style
 	StatsPanelStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(mutedColor).
-			Padding(1, 2).
-			MarginTop(1)
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(mutedColor).
+		Padding(1, 2).
+		MarginTop(1)
 
 	// Stats title style
 	StatsTitleStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true).
-			Underline(true)
+		Foreground(accentColor).
+		Bold(true).
+		Underline(true)
 
 	// Stats label style
 	StatsLabelStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Width(20)
+		Foreground(mutedColor).
+		Width(20)
 
 	// Stats value style
 	StatsValueStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+		Foreground(primaryColor).
+		Bold(true)
 
 	// Error message style
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(errorColor).
+		Bold(true).
+		Padding(0, 1)
 
 	// Success message style
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(successColor).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(successColor).
+		Bold(true).
+		Padding(0, 1)
 
 	// Typing indicator style
 	TypingStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Italic(true)
+		Foreground(mutedColor).
+		Italic(true)
 
 	// Help style
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Italic(true).
-			Padding(0, 1)
+		Foreground(mutedColor).
+		Italic(true).
+		Padding(0, 1)
 
 	// Code block style
 	CodeBlockStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("235")).
-			Foreground(lipgloss.Color("252")).
-			Padding(1, 2).
-			MarginTop(1).
-			MarginBottom(1)
+		Background(lipgloss.Color(p.CodeBackground)).
+		Foreground(lipgloss.Color(p.Text)).
+		Padding(1, 2).
+		MarginTop(1).
+		MarginBottom(1)
 
 	// Command style (for slash commands)
+	SelectedMessageStyle = lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder(), false, false, false, true).
+		BorderForeground(accentColor).
+		PaddingLeft(1)
+
 	CommandStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true)
+		Foreground(accentColor).
+		Bold(true)
 
 	// Divider style
 	DividerStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Faint(true)
+		Foreground(mutedColor).
+		Faint(true)
 
 	// Title style
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true).
-			Underline(true).
-			Padding(0, 1)
-)
+		Foreground(primaryColor).
+		Bold(true).
+		Underline(true).
+		Padding(0, 1)
+
+	components.ApplyPalette(p)
+}
 
 // RenderDivider creates a horizontal divider
 func RenderDivider(width int) string {
@@ -137,8 +184,14 @@ func RenderHelp() string {
 	return HelpStyle.Render("Type a message or /help for commands • Ctrl+C to exit • Ctrl+S to toggle stats")
 }
 
-// RenderBanner renders a banner with program info
-func RenderBanner(appName, appDesc, version, model, baseURL string) string {
+// bannerWidth is the width of the banner content on wide terminals
+const bannerWidth = 48
+
+// RenderBanner renders a banner with program info, fitting it into width columns
+func RenderBanner(appName, appDesc, version, model, baseURL, title string, width int) string {
+	// The border and padding take 6 columns
+	inner := max(1, min(bannerWidth, width-6))
+
 	bannerStyle := lipgloss.NewStyle().
 		Border(lipgloss.DoubleBorder()).
 		BorderForeground(primaryColor).
@@ -156,7 +209,8 @@ func RenderBanner(appName, appDesc, version, model, baseURL string) string {
 		Align(lipgloss.Center)
 
 	infoStyle := lipgloss.NewStyle().
-		Foreground(mutedColor)
+		Foreground(mutedColor).
+		Width(inner)
 
 	var content strings.Builder
 
@@ -166,9 +220,11 @@ func RenderBanner(appName, appDesc, version, model, baseURL string) string {
 ░▀▀▀░▀░▀░▀░▀░░▀░░░░░▀░░▀▀▀░▀▀▀
 `
 
-	content.WriteString(asciiArtStyle.Width(48).Render(asciiArt))
-	content.WriteString("\n")
-	content.WriteString(subtitleStyle.Width(48).Render(appDesc))
+	if inner >= lipgloss.Width(asciiArt) {
+		content.WriteString(asciiArtStyle.Width(inner).Render(asciiArt))
+		content.WriteString("\n")
+	}
+	content.WriteString(subtitleStyle.Width(inner).Render(appDesc))
 	content.WriteString("\n\n")
 
 	// Info
@@ -177,10 +233,15 @@ func RenderBanner(appName, appDesc, version, model, baseURL string) string {
 	content.WriteString(infoStyle.Render(fmt.Sprintf("Model:    %s", model)))
 	content.WriteString("\n")
 	content.WriteString(infoStyle.Render(fmt.Sprintf("Endpoint: %s", baseURL)))
-	content.WriteString("\n\n")
+	content.WriteString("\n")
+	if title != "" {
+		content.WriteString(infoStyle.Render(fmt.Sprintf("Session:  %s", title)))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
 
 	// Quick help
-	content.WriteString(HelpStyle.Render("Type /help for commands • Ctrl+C to exit"))
+	content.WriteString(HelpStyle.Width(inner).Render("Type /help for commands • Ctrl+C to exit"))
 
 	return bannerStyle.Render(content.String())
 }
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/theme"
+)
+
+// setTheme switches the interface colors and the markdown style to a theme
+func (m *ChatModel) setTheme(name string) error {
+	t, err := theme.Load(name, "")
+	if err != nil {
+		return err
+	}
+	if err := m.messageComp.SetTheme(t); err != nil {
+		return err
+	}
+
+	ApplyTheme(t)
+	m.theme = t
+	m.config.UI.Theme = t.Name
+	if m.compare != nil {
+		// Compare columns have their own renderer
+		m.compare.renderer = nil
+	}
+	return nil
+}
+
+// themeReport lists the available themes for /theme
+func (m *ChatModel) themeReport() string {
+	var b strings.Builder
+	b.WriteString("Themes:\n\n")
+	for _, name := range theme.Names("") {
+		if name == m.theme.Name {
+			fmt.Fprintf(&b, "- **%s** (current)\n", name)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", name)
+		}
+	}
+	if dir, err := theme.DefaultDir(); err == nil {
+		fmt.Fprintf(&b, "\nAdd your own as YAML files in `%s`", dir)
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/version"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// maxTitleLength caps generated titles
+	maxTitleLength = 60
+	// titleExcerpt is the number of runes of each message sent for titling
+	titleExcerpt = 1000
+	// titleMaxTokens limits the title response
+	titleMaxTokens = 64
+)
+
+// titleMsg carries a generated session title
+type titleMsg struct {
+	conversation *session.Tree
+	title        string
+	stats        *llm.RequestStats
+	err          error
+}
+
+// generateTitle asks the title model to name the conversation after its first
+// exchange. It runs as a side request and never touches the transcript.
+func (m *ChatModel) generateTitle() tea.Cmd {
+	if !m.config.Sessions.AutoTitle || m.title != "" || m.titleRequested {
+		return nil
+	}
+
+	var user, assistant string
+	for _, msg := range m.messages {
+		switch {
+		case msg.Role == "user" && user == "":
+			user = msg.Content
+		case msg.Role == "assistant" && user != "" && assistant == "":
+			assistant = msg.Content
+		}
+	}
+	if user == "" || assistant == "" {
+		return nil
+	}
+	m.titleRequested = true
+
+	model := m.config.Sessions.TitleModel
+	if model == "" {
+		model = m.client.GetModel()
+	}
+	client := llm.NewOpenAIClient(m.config.APIKey, m.config.BaseURL, model, 0.3, titleMaxTokens)
+	client.SetLogger(m.logger.Slog())
+
+	messages := []llm.Message{
+		{
+			Role: "system",
+			Content: "You name chat conversations. Reply with a short, specific title of at most six words " +
+				"for the conversation below. No quotes, no trailing punctuation, title only.",
+		},
+		{
+			Role:    "user",
+			Content: "User: " + excerpt(user, titleExcerpt) + "\n\nAssistant: " + excerpt(assistant, titleExcerpt),
+		},
+	}
+	tree := m.tree
+
+	return func() tea.Msg {
+		title, stats, err := client.Chat(context.Background(), messages)
+		return titleMsg{conversation: tree, title: cleanTitle(title), stats: stats, err: err}
+	}
+}
+
+// applyTitle stores a generated title unless the conversation changed meanwhile
+func (m *ChatModel) applyTitle(msg titleMsg) {
+	if msg.conversation != m.tree {
+		return
+	}
+	if msg.err == nil {
+		m.logRequest(msg.stats)
+	}
+	if m.title != "" {
+		return
+	}
+	if msg.err != nil || msg.title == "" {
+		// Titles are a convenience; fall back to the first message
+		m.logger.Slog().Warn("title generation failed", "error", errorString(msg.err))
+		return
+	}
+	m.setTitle(msg.title)
+}
+
+// setTitle names the session and saves it
+func (m *ChatModel) setTitle(title string) {
+	m.title = title
+	m.autosave()
+}
+
+// windowTitle returns the terminal window title
+func (m *ChatModel) windowTitle() string {
+	if m.title == "" {
+		return version.AppName
+	}
+	return m.title + " — " + version.AppName
+}
+
+// syncWindowTitle returns a command updating the terminal title when it changed
+func (m *ChatModel) syncWindowTitle() tea.Cmd {
+	title := m.windowTitle()
+	if title == m.shownTitle {
+		return nil
+	}
+	m.shownTitle = title
+	return tea.SetWindowTitle(title)
+}
+
+// cleanTitle normalizes a model-written title
+func cleanTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if line, _, found := strings.Cut(title, "\n"); found {
+		title = line
+	}
+	title = strings.TrimPrefix(title, "Title:")
+	title = strings.Trim(strings.TrimSpace(title), `"'*#.`)
+	return session.Truncate(title, maxTitleLength)
+}
+
+// excerpt returns the first max runes of text
+func excerpt(text string, max int) string {
+	if runes := []rune(text); len(runes) > max {
+		return string(runes[:max]) + "…"
+	}
+	return text
+}
+
+// errorString returns the message of err, or "empty title" if nil
+func errorString(err error) string {
+	if err == nil {
+		return "empty title"
+	}
+	return err.Error()
+}
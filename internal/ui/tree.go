@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/ui/components"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treePickerHeight is the number of nodes shown at once in /tree
+const treePickerHeight = 15
+
+// syncMessages refreshes the cached active path after the tree changed
+func (m *ChatModel) syncMessages() {
+	m.path = m.tree.Path()
+	m.messages = m.tree.Messages()
+	m.window = nil
+	m.codeFocus = 0
+	m.refreshUsage()
+}
+
+// appendMessage adds a message at the end of the active path
+func (m *ChatModel) appendMessage(msg session.Message) {
+	m.tree.Append(msg)
+	m.syncMessages()
+}
+
+// resetConversation starts a new, empty conversation with the system prompt
+func (m *ChatModel) resetConversation() {
+	m.tree = session.NewTree()
+	if m.systemPrompt != "" {
+		m.tree.Append(session.NewMessage("system", m.systemPrompt))
+	}
+	m.branchCursor = -1
+	m.syncMessages()
+}
+
+// branchPoints returns the nodes of the active path that have sibling branches
+func (m *ChatModel) branchPoints() []int {
+	var points []int
+	for _, id := range m.path {
+		if _, count := m.tree.Siblings(id); count > 1 {
+			points = append(points, id)
+		}
+	}
+	return points
+}
+
+// activeBranchPoint returns the branch point selected for navigation,
+// defaulting to the last one on the active path, or -1 if there are none
+func (m *ChatModel) activeBranchPoint() int {
+	points := m.branchPoints()
+	if len(points) == 0 {
+		return -1
+	}
+	for _, id := range points {
+		if id == m.branchCursor {
+			return id
+		}
+	}
+	return points[len(points)-1]
+}
+
+// moveBranchCursor selects the previous (delta < 0) or next branch point
+func (m *ChatModel) moveBranchCursor(delta int) {
+	points := m.branchPoints()
+	if len(points) == 0 {
+		return
+	}
+
+	current := m.activeBranchPoint()
+	index := len(points) - 1
+	for i, id := range points {
+		if id == current {
+			index = i
+		}
+	}
+	index += delta
+	if index < 0 {
+		index = 0
+	} else if index >= len(points) {
+		index = len(points) - 1
+	}
+	m.branchCursor = points[index]
+}
+
+// switchBranch moves to the previous (delta < 0) or next sibling branch at the selected turn
+func (m *ChatModel) switchBranch(delta int) {
+	id := m.activeBranchPoint()
+	if id < 0 {
+		m.err = fmt.Errorf("no branches in this conversation")
+		return
+	}
+	m.branchCursor = m.tree.SwitchSibling(id, delta)
+	m.syncMessages()
+	m.err = nil
+}
+
+// renderBranchIndicator renders the "⎇ 2/3" marker of a message that has sibling branches
+func (m *ChatModel) renderBranchIndicator(pathIndex int) string {
+	id := m.path[pathIndex]
+	index, count := m.tree.Siblings(id)
+	if count < 2 {
+		return ""
+	}
+
+	label := fmt.Sprintf("⎇ %d/%d", index+1, count)
+	if id == m.activeBranchPoint() {
+		return CommandStyle.Render(label) + HelpStyle.Render(fmt.Sprintf("%s/%s switch • %s/%s select turn",
+			m.keys.BranchPrev.Help().Key, m.keys.BranchNext.Help().Key, m.keys.BranchUp.Help().Key, m.keys.BranchDown.Help().Key))
+	}
+	return HelpStyle.Render(label)
+}
+
+// renderBranchBar shows the selected branch point while navigating branches in
+// inline mode, where its indicator has already scrolled into the scrollback
+func (m *ChatModel) renderBranchBar() string {
+	if m.fullscreen || m.branchCursor < 0 {
+		return ""
+	}
+	id := m.activeBranchPoint()
+	for i, pathID := range m.path {
+		if pathID == id {
+			return m.renderBranchIndicator(i) + "\n" +
+				HelpStyle.Render(fmt.Sprintf("%s: %s", m.messages[i].Role, session.Truncate(m.messages[i].Content, previewLength))) + "\n"
+		}
+	}
+	return ""
+}
+
+// openTreePicker shows the branch structure of the conversation for /tree
+func (m *ChatModel) openTreePicker() error {
+	if m.tree.Len() == 0 {
+		return fmt.Errorf("conversation is empty")
+	}
+
+	var items []components.PickerItem
+	selected := 0
+	leaf := m.tree.Leaf()
+	m.tree.Walk(func(node *session.Node, depth int, active bool) {
+		marker := "○"
+		if active {
+			marker = "●"
+		}
+		if node.ID == leaf {
+			selected = len(items)
+		}
+
+		detail := ""
+		if index, count := m.tree.Siblings(node.ID); count > 1 {
+			detail = fmt.Sprintf("branch %d/%d", index+1, count)
+		}
+
+		items = append(items, components.PickerItem{
+			Key:    strconv.Itoa(node.ID),
+			Label:  fmt.Sprintf("%s%s %-9s %s", strings.Repeat("│ ", depth), marker, node.Message.Role, session.Truncate(node.Message.Content, 50)),
+			Detail: detail,
+		})
+	})
+
+	m.openPicker("Conversation tree (● active path)", items, treePickerHeight, func(item components.PickerItem) tea.Cmd {
+		id, err := strconv.Atoi(item.Key)
+		if err != nil || m.tree.Node(id) == nil {
+			return nil
+		}
+		m.tree.Activate(id)
+		m.branchCursor = id
+		m.syncMessages()
+		return nil
+	})
+	m.picker.SetSelected(selected)
+	return nil
+}
@@ -0,0 +1,175 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/config"
+	"github.com/LETHEVIET/chat-tui/internal/keymap"
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	"github.com/LETHEVIET/chat-tui/internal/usage"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// maxUndo is the number of snapshots kept for /undo
+	maxUndo = 50
+	// confirmClearTurns is the number of turns above which /clear asks first
+	confirmClearTurns = 3
+)
+
+// snapshot is the conversation state before a mutating command. Only
+// snapshots taken before another session replaces the conversation hold the
+// model settings: other commands, such as /temp, are not undone.
+type snapshot struct {
+	label        string
+	tree         *session.Tree
+	settings     *modelSettings
+	ledger       *usage.Ledger // shared with later snapshots of the same session
+	systemPrompt string
+	sessionID    string
+	source       string
+	createdAt    time.Time
+	title        string
+}
+
+// modelSettings are the config and client that restoring a session replaces
+type modelSettings struct {
+	config      config.Config
+	client      llm.Client
+	temperature float64 // the client's, which /temp changes in place
+}
+
+// confirmState is a pending yes/no question
+type confirmState struct {
+	prompt string
+	onYes  func() tea.Cmd
+}
+
+// takeSnapshot captures the current conversation state, and the model
+// settings if withSettings is set
+func (m *ChatModel) takeSnapshot(label string, withSettings bool) snapshot {
+	var settings *modelSettings
+	if withSettings {
+		settings = &modelSettings{
+			config:      *m.config,
+			client:      m.client,
+			temperature: m.client.GetTemperature(),
+		}
+	}
+	return snapshot{
+		label:        label,
+		tree:         m.tree.Clone(),
+		settings:     settings,
+		ledger:       m.ledger,
+		systemPrompt: m.systemPrompt,
+		sessionID:    m.sessionID,
+		source:       m.source,
+		createdAt:    m.createdAt,
+		title:        m.title,
+	}
+}
+
+// checkpoint records the state before a mutation so /undo can restore it
+func (m *ChatModel) checkpoint(label string) {
+	m.pushUndo(m.takeSnapshot(label, false))
+}
+
+// checkpointSession records the state before another session replaces the
+// conversation, including the model settings it brings along
+func (m *ChatModel) checkpointSession(label string) {
+	m.pushUndo(m.takeSnapshot(label, true))
+}
+
+// pushUndo adds a snapshot to the undo stack and clears the redo stack
+func (m *ChatModel) pushUndo(s snapshot) {
+	m.undoStack = append(m.undoStack, s)
+	if len(m.undoStack) > maxUndo {
+		m.undoStack = m.undoStack[len(m.undoStack)-maxUndo:]
+	}
+	m.redoStack = nil
+}
+
+// restoreSnapshot replaces the conversation state with s
+func (m *ChatModel) restoreSnapshot(s snapshot) {
+	m.tree = s.tree
+	// Undoing within a session keeps the requests made since; undoing a clear
+	// or a session switch brings back the ledger of the other session
+	m.ledger = s.ledger
+	if s.settings != nil {
+		cfg := s.settings.config
+		m.config = &cfg
+		m.client = s.settings.client
+		m.client.SetTemperature(s.settings.temperature)
+	}
+	m.systemPrompt = s.systemPrompt
+	m.sessionID = s.sessionID
+	m.source = s.source
+	m.createdAt = s.createdAt
+	m.title = s.title
+	m.branchCursor = -1
+	m.focusMessage = -1
+	m.syncMessages()
+	m.autosave()
+}
+
+// undo reverts the last mutating command and returns its label
+func (m *ChatModel) undo() (string, error) {
+	if len(m.undoStack) == 0 {
+		return "", fmt.Errorf("nothing to undo")
+	}
+
+	s := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.redoStack = append(m.redoStack, m.takeSnapshot(s.label, s.settings != nil))
+	m.restoreSnapshot(s)
+	return s.label, nil
+}
+
+// redo re-applies the last undone command and returns its label
+func (m *ChatModel) redo() (string, error) {
+	if len(m.redoStack) == 0 {
+		return "", fmt.Errorf("nothing to redo")
+	}
+
+	s := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	m.undoStack = append(m.undoStack, m.takeSnapshot(s.label, s.settings != nil))
+	m.restoreSnapshot(s)
+	return s.label, nil
+}
+
+// runUndo runs undo or redo and reports the result, e.g. "Undid delete"
+func (m *ChatModel) runUndo(verb string, action func() (string, error)) {
+	label, err := action()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.notice = fmt.Sprintf("%s %s", verb, label)
+}
+
+// updateConfirm answers a pending confirmation: the confirm key runs it,
+// anything else cancels
+func (m *ChatModel) updateConfirm(msg tea.KeyMsg) tea.Cmd {
+	confirm := m.confirm
+	m.confirm = nil
+	if key.Matches(msg, m.keys.Confirm) {
+		return confirm.onYes()
+	}
+	m.notice = "Cancelled"
+	return nil
+}
+
+// renderConfirm renders the pending confirmation prompt with the key that
+// accepts it
+func (m *ChatModel) renderConfirm() string {
+	hint := " (any key cancels)"
+	if keys := m.keys.Confirm.Keys(); len(keys) > 0 {
+		hint = fmt.Sprintf(" (%s/N)", keymap.Describe(keys[:1]))
+	}
+	return ErrorStyle.Render(m.confirm.prompt) + HelpStyle.Render(hint) + "\n"
+}
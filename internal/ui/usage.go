@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+	"github.com/LETHEVIET/chat-tui/internal/usage"
+)
+
+// pricing returns the configured pricing of model
+func (m *ChatModel) pricing(model string) (usage.Pricing, bool) {
+	prices := make(map[string]usage.Pricing, len(m.config.Pricing))
+	for _, p := range m.config.Pricing {
+		prices[strings.ToLower(p.Model)] = usage.Pricing{Input: p.Input, Output: p.Output}
+	}
+	return usage.LookupPricing(prices, model)
+}
+
+// recordStats prices a finished request, adds it to the session ledger and
+// shows it in the stats panel
+func (m *ChatModel) recordStats(stats *llm.RequestStats) {
+	m.logRequest(stats)
+	m.stats.SetStats(stats)
+}
+
+// logRequest prices a finished request and adds it to the session ledger
+// without showing it, as for summaries, titles and losing compare answers
+func (m *ChatModel) logRequest(stats *llm.RequestStats) {
+	if stats == nil {
+		return
+	}
+	if p, ok := m.pricing(stats.Model); ok {
+		stats.CostEstimate = p.Cost(stats.InputTokens, stats.OutputTokens)
+	}
+	m.ledger.Add(stats)
+	m.refreshUsage()
+}
+
+// refreshUsage shows the session totals and history of the ledger
+func (m *ChatModel) refreshUsage() {
+	totals := m.ledger.Totals
+	m.stats.SetTotals(&totals)
+	m.stats.SetHistory(m.ledger.Log)
+}
+
+// tokensReport renders the per-turn token table and session totals for /tokens
+func (m *ChatModel) tokensReport() string {
+	var b strings.Builder
+
+	b.WriteString("| # | Model | Input | Output | TTFT | Speed | Cost |\n")
+	b.WriteString("|---|---|---:|---:|---:|---:|---:|\n")
+	turn := 0
+	for _, msg := range m.messages {
+		if msg.Role == "user" {
+			turn++
+		}
+		if msg.Stats == nil {
+			continue
+		}
+		st := msg.Stats
+		fmt.Fprintf(&b, "| %d | %s | %d | %d | %.2fs | %.1f tok/s | %s |\n",
+			turn, st.Model, st.InputTokens, st.OutputTokens,
+			st.TimeToFirstToken.Seconds(), usage.Speed(st), formatCost(st.CostEstimate))
+	}
+
+	totals := m.stats.Totals()
+	if totals == nil || totals.Requests == 0 {
+		return "No requests yet"
+	}
+
+	fmt.Fprintf(&b, "\n**Session** (%d requests, including deleted messages and side requests): %d input + %d output = %d tokens, %s\n\n",
+		totals.Requests, totals.InputTokens, totals.OutputTokens, totals.TotalTokens(), formatCost(totals.Cost))
+	fmt.Fprintf(&b, "TTFT avg %.2fs • p50 %.2fs • p95 %.2fs\n\n",
+		totals.AvgTTFT.Seconds(), totals.P50TTFT.Seconds(), totals.P95TTFT.Seconds())
+	fmt.Fprintf(&b, "Speed avg %.1f • p50 %.1f • p95 %.1f tok/s",
+		totals.AvgSpeed, totals.P50Speed, totals.P95Speed)
+
+	return b.String()
+}
+
+// costReport renders the estimated session cost per model for /cost
+func (m *ChatModel) costReport() string {
+	type modelCost struct {
+		requests int
+		tokens   int
+		cost     float64
+	}
+	byModel := map[string]*modelCost{}
+	for _, st := range m.ledger.Log {
+		mc := byModel[st.Model]
+		if mc == nil {
+			mc = &modelCost{}
+			byModel[st.Model] = mc
+		}
+		mc.requests++
+		mc.tokens += st.InputTokens + st.OutputTokens
+		mc.cost += st.CostEstimate
+	}
+	if len(byModel) == 0 {
+		return "No requests yet"
+	}
+
+	models := make([]string, 0, len(byModel))
+	for model := range byModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	var b strings.Builder
+	b.WriteString("| Model | Requests | Tokens | Cost |\n")
+	b.WriteString("|---|---:|---:|---:|\n")
+	var unpriced []string
+	for _, model := range models {
+		mc := byModel[model]
+		cost := formatCost(mc.cost)
+		if _, ok := m.pricing(model); !ok {
+			cost = "n/a"
+			unpriced = append(unpriced, model)
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", model, mc.requests, mc.tokens, cost)
+	}
+
+	fmt.Fprintf(&b, "\n**Estimated total:** %s", formatCost(m.stats.Totals().Cost))
+	if len(unpriced) > 0 {
+		fmt.Fprintf(&b, "\n\nNo pricing configured for %s (add it under `pricing:` in .chat-tui.yaml)", strings.Join(unpriced, ", "))
+	}
+
+	return b.String()
+}
+
+// formatCost formats a dollar amount, keeping precision for small values
+func formatCost(cost float64) string {
+	if cost == 0 {
+		return "$0"
+	}
+	if cost < 0.01 {
+		return fmt.Sprintf("$%.6f", cost)
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
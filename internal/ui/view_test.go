@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LETHEVIET/chat-tui/internal/config"
+	"github.com/LETHEVIET/chat-tui/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// benchmarkReply is a typical assistant answer with prose and code
+const benchmarkReply = "Here is how to read a file in Go:\n\n" +
+	"```go\ndata, err := os.ReadFile(path)\nif err != nil {\n\treturn err\n}\nfmt.Println(string(data))\n```\n\n" +
+	"- `os.ReadFile` reads the whole file\n- check the **error** before using `data`\n"
+
+// benchmarkPartial is the start of the streaming reply
+const benchmarkPartial = "Partial answer with `code` and **bold** text"
+
+// newBenchmarkModel returns a chat model with turns finished exchanges and a
+// reply streaming, as after the first frames of a long session
+func newBenchmarkModel(b *testing.B, turns int, fullscreen bool) *ChatModel {
+	b.Helper()
+	cfg := &config.Config{
+		Model:   "bench",
+		UI:      config.UIConfig{Theme: "dark", SyntaxHighlight: true, LineNumbers: true, Fullscreen: fullscreen},
+		Context: config.ContextConfig{Policy: "sliding"},
+	}
+	cfg.Sessions.Dir = b.TempDir()
+
+	m, err := NewChatModel(cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	for i := 0; i < turns; i++ {
+		m.appendMessage(session.NewMessage("user", fmt.Sprintf("Question %d: how do I read a file?", i)))
+		m.appendMessage(session.NewMessage("assistant", benchmarkReply))
+	}
+	m.Update(scrollbackMsg{})
+	m.streaming = true
+	m.streamContent = benchmarkPartial
+	m.View() // warm the render cache
+	return m
+}
+
+// BenchmarkView measures one frame while a reply streams. Inline mode keeps
+// finished messages in the scrollback and full-screen mode caches them, so the
+// cost should not grow with the length of the history in either.
+func BenchmarkView(b *testing.B) {
+	for _, mode := range []string{"inline", "fullscreen"} {
+		for _, turns := range []int{2, 20, 200} {
+			b.Run(fmt.Sprintf("%s/turns=%d", mode, turns), func(b *testing.B) {
+				m := newBenchmarkModel(b, turns, mode == "fullscreen")
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					// Each frame shows a new chunk, as while streaming
+					m.streamContent = benchmarkPartial + strings.Repeat(".", i%8)
+					m.View()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package usage
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LETHEVIET/chat-tui/internal/llm"
+)
+
+// Pricing is the price of a model in dollars per million tokens
+type Pricing struct {
+	Input  float64
+	Output float64
+}
+
+// Cost returns the price of a request with the given token counts
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1e6
+}
+
+// LookupPricing finds the pricing of model in prices. Keys match exactly or
+// as the longest prefix (so "gpt-4o" also prices "gpt-4o-2024-08-06").
+func LookupPricing(prices map[string]Pricing, model string) (Pricing, bool) {
+	model = strings.ToLower(model)
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+
+	best, found := "", false
+	for name := range prices {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	return prices[best], found
+}
+
+// Totals aggregates the requests of a session
+type Totals struct {
+	Requests     int           `json:"requests"`
+	InputTokens  int           `json:"input_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	Cost         float64       `json:"cost"`
+	MinTTFT      time.Duration `json:"min_ttft"`
+	MaxTTFT      time.Duration `json:"max_ttft"`
+	AvgTTFT      time.Duration `json:"avg_ttft"`
+	P50TTFT      time.Duration `json:"p50_ttft"`
+	P95TTFT      time.Duration `json:"p95_ttft"`
+	MinSpeed     float64       `json:"min_speed"`
+	MaxSpeed     float64       `json:"max_speed"`
+	AvgSpeed     float64       `json:"avg_speed"`
+	P50Speed     float64       `json:"p50_speed"`
+	P95Speed     float64       `json:"p95_speed"`
+}
+
+// Ledger records every request made for a session, including those whose
+// messages were deleted or undone, losing compare answers and side requests
+// such as summaries and titles
+type Ledger struct {
+	Totals
+	Log []*llm.RequestStats `json:"log,omitempty"`
+}
+
+// NewLedger returns a ledger of the given requests, ordered by start time
+func NewLedger(stats []*llm.RequestStats) *Ledger {
+	l := &Ledger{}
+	for _, s := range stats {
+		if s != nil {
+			l.Log = append(l.Log, s)
+		}
+	}
+	sort.SliceStable(l.Log, func(i, j int) bool {
+		return l.Log[i].StartTime.Before(l.Log[j].StartTime)
+	})
+	l.Totals = *Summarize(l.Log)
+	return l
+}
+
+// Add records a finished request
+func (l *Ledger) Add(stats *llm.RequestStats) {
+	if stats == nil {
+		return
+	}
+	l.Log = append(l.Log, stats)
+	l.Totals = *Summarize(l.Log)
+}
+
+// TotalTokens returns the input and output tokens combined
+func (t *Totals) TotalTokens() int {
+	return t.InputTokens + t.OutputTokens
+}
+
+// Speed returns the generation speed of a request in tokens per second
+func Speed(stats *llm.RequestStats) float64 {
+	if stats.PostFirstTokenSpeed > 0 {
+		return stats.PostFirstTokenSpeed
+	}
+	return stats.TokensPerSec
+}
+
+// Summarize aggregates the stats of every request; nil entries are skipped
+func Summarize(stats []*llm.RequestStats) *Totals {
+	t := &Totals{}
+	var ttfts, speeds []float64
+
+	for _, s := range stats {
+		if s == nil {
+			continue
+		}
+		t.Requests++
+		t.InputTokens += s.InputTokens
+		t.OutputTokens += s.OutputTokens
+		t.Cost += s.CostEstimate
+		if s.TimeToFirstToken > 0 {
+			ttfts = append(ttfts, float64(s.TimeToFirstToken))
+		}
+		if speed := Speed(s); speed > 0 {
+			speeds = append(speeds, speed)
+		}
+	}
+
+	minTTFT, maxTTFT := MinMax(ttfts)
+	t.MinTTFT, t.MaxTTFT = time.Duration(minTTFT), time.Duration(maxTTFT)
+	t.AvgTTFT = time.Duration(mean(ttfts))
+	t.P50TTFT = time.Duration(Percentile(ttfts, 50))
+	t.P95TTFT = time.Duration(Percentile(ttfts, 95))
+	t.MinSpeed, t.MaxSpeed = MinMax(speeds)
+	t.AvgSpeed = mean(speeds)
+	t.P50Speed = Percentile(speeds, 50)
+	t.P95Speed = Percentile(speeds, 95)
+
+	return t
+}
+
+// Percentile returns the p-th percentile (nearest rank) of values, or 0 if empty
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// MinMax returns the smallest and largest of values, or zeros if empty
+func MinMax(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values[1:] {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}